@@ -0,0 +1,100 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryBackgroundColor asks the terminal for its background color using OSC 11
+// request. The terminal is put into raw mode for the time of the query.
+// ErrNoReply is returned if the terminal doesn't answer within timeout.
+func (t *Terminal) QueryBackgroundColor(timeout time.Duration) (Color, error) {
+	return t.queryColor(11, timeout)
+}
+
+// QueryForegroundColor asks the terminal for its default text color using OSC 10
+// request. The terminal is put into raw mode for the time of the query.
+// ErrNoReply is returned if the terminal doesn't answer within timeout.
+func (t *Terminal) QueryForegroundColor(timeout time.Duration) (Color, error) {
+	return t.queryColor(10, timeout)
+}
+
+// HasDarkBackground reports whether the terminal background is dark, so that
+// light colors should be chosen for text. The terminal is asked first, and if
+// it doesn't answer, COLORFGBG environment variable set by some terminals is
+// checked. Dark background is assumed if nothing is known.
+func (t *Terminal) HasDarkBackground() bool {
+	if c, err := t.QueryBackgroundColor(DefaultQueryTimeout); err == nil {
+		l, _, _ := c.OKLCH()
+		return l < 0.6
+	}
+	if dark, ok := darkFromColorFgBg(os.Getenv("COLORFGBG")); ok {
+		return dark
+	}
+	return true
+}
+
+// queryColor sends "OSC <code> ; ? ST" request and parses
+// "OSC <code> ; rgb:<r>/<g>/<b> ST" reply.
+func (t *Terminal) queryColor(code int, timeout time.Duration) (Color, error) {
+	prefix := []byte(ESC + "]" + strconv.Itoa(code) + ";")
+	// ESC ] <code> ; ? ESC \
+	reply, err := t.queryWithFallback(string(prefix)+"?"+ESC+"\\", timeout, func(seq []byte) bool {
+		return bytes.HasPrefix(seq, prefix)
+	})
+	if err != nil {
+		return Color{}, err
+	}
+	return parseColorReply(string(reply[len(prefix):]))
+}
+
+// parseColorReply parses X11 color specification from the reply to OSC 10 and
+// OSC 11 requests, terminated with either BEL or ST. Components are 1 to 4 hex
+// digits each.
+func parseColorReply(s string) (Color, error) {
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "\x07"), ESC+"\\")
+	var spec string
+	switch {
+	case strings.HasPrefix(s, "rgb:"):
+		spec = s[len("rgb:"):]
+	case strings.HasPrefix(s, "rgba:"):
+		spec = s[len("rgba:"):]
+	default:
+		return ParseColor(s)
+	}
+	parts := strings.Split(spec, "/")
+	if len(parts) < 3 {
+		return Color{}, errors.New("invalid color reply " + strconv.Quote(s))
+	}
+	var rgb [3]int
+	for i := range rgb {
+		if len(parts[i]) == 0 || len(parts[i]) > 4 {
+			return Color{}, errors.New("invalid color reply " + strconv.Quote(s))
+		}
+		v, err := strconv.ParseUint(parts[i], 16, 16)
+		if err != nil {
+			return Color{}, errors.New("invalid color reply " + strconv.Quote(s))
+		}
+		full := uint64(1)<<(4*uint(len(parts[i]))) - 1
+		rgb[i] = int((v*255 + full/2) / full)
+	}
+	return RGB(rgb[0], rgb[1], rgb[2]), nil
+}
+
+// darkFromColorFgBg parses COLORFGBG variable in "fg;bg" or "fg;default;bg"
+// form, where bg is one of the 16 named colors.
+func darkFromColorFgBg(s string) (dark bool, ok bool) {
+	if s == "" {
+		return false, false
+	}
+	parts := strings.Split(s, ";")
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	return bg != 7 && bg < 9, true
+}
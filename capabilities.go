@@ -0,0 +1,143 @@
+package terminal
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Capabilities describes what the terminal has reported about itself in reply
+// to identification queries. Fields are left empty for the queries the
+// terminal doesn't answer.
+type Capabilities struct {
+	// Name and Version of the terminal as reported by XTVERSION, for instance
+	// "xterm" and "370", "kitty" and "0.26.5" or "WezTerm" and "20220807".
+	Name, Version string
+
+	// ConformanceLevel is the first parameter of "Primary Device Attributes"
+	// (DA1) reply: 1 for VT100, 62 for VT220, 63 for VT320 and so on.
+	ConformanceLevel int
+	// Features are the rest of DA1 reply parameters, such as 4 for sixel
+	// graphics or 22 for ANSI color.
+	Features []int
+
+	// Type is the first parameter of "Secondary Device Attributes" (DA2) reply,
+	// for instance 0 for VT100, 41 for xterm, 65 for VTE based terminals or 84
+	// for tmux.
+	Type int
+	// Firmware is the second parameter of DA2 reply, usually the version of the
+	// terminal.
+	Firmware int
+
+	// Sixel reports whether the terminal can display sixel graphics.
+	Sixel bool
+	// TrueColor reports whether the terminal is known to display RGB colors
+	// without approximating them.
+	TrueColor bool
+}
+
+// HasFeature reports whether DA1 reply contains feature n.
+func (c Capabilities) HasFeature(n int) bool {
+	for _, f := range c.Features {
+		if f == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities returns capabilities of the terminal, probing them once using
+// ProbeCapabilities with DefaultQueryTimeout. Empty Capabilities are returned if
+// the output is not a terminal or the terminal doesn't answer.
+func (t *Terminal) Capabilities() Capabilities {
+	t.capsOnce.Do(func() {
+		t.caps, _ = t.ProbeCapabilities(DefaultQueryTimeout)
+	})
+	return t.caps
+}
+
+// ProbeCapabilities asks the terminal to identify itself using XTVERSION
+// (CSI > 0 q), Secondary Device Attributes (CSI > c) and Primary Device
+// Attributes (CSI c) requests. Since every terminal answers the last one,
+// waiting for the whole timeout only happens when the terminal doesn't answer
+// at all. The terminal is put into raw mode for the time of the query.
+func (t *Terminal) ProbeCapabilities(timeout time.Duration) (Capabilities, error) {
+	var c Capabilities
+	seqs, err := t.exchange(CSI+">0q"+CSI+">c"+requestPrimaryAttributes, timeout, isPrimaryAttributes)
+	if err != nil && len(seqs) == 0 {
+		return c, err
+	}
+	for _, seq := range seqs {
+		switch {
+		case isPrimaryAttributes(seq):
+			// ESC [ ? <level> ; <feature> ; ... c
+			params := parseParams(seq[3 : len(seq)-1])
+			if len(params) > 0 {
+				c.ConformanceLevel = params[0]
+				c.Features = params[1:]
+			}
+		case len(seq) > 3 && seq[1] == '[' && seq[2] == '>' && seq[len(seq)-1] == 'c':
+			// ESC [ > <type> ; <firmware> ; <rom> c
+			params := parseParams(seq[3 : len(seq)-1])
+			if len(params) > 0 {
+				c.Type = params[0]
+			}
+			if len(params) > 1 {
+				c.Firmware = params[1]
+			}
+		case bytes.HasPrefix(seq, []byte(ESC+"P>|")):
+			// ESC P > | <name and version> ESC \
+			c.Name, c.Version = parseTerminalVersion(string(bytes.TrimSuffix(seq[4:], []byte(ESC+"\\"))))
+		}
+	}
+	c.Sixel = c.HasFeature(4)
+	c.TrueColor = isTrueColorTerminal(c)
+	return c, err
+}
+
+// parseTerminalVersion splits XTVERSION reply, such as "xterm(370)",
+// "tmux 3.3a" or "kitty(0.26.5)", into the name and version.
+func parseTerminalVersion(s string) (name, version string) {
+	if i := strings.IndexByte(s, '('); i > 0 && strings.HasSuffix(s, ")") {
+		return s[:i], s[i+1 : len(s)-1]
+	}
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// isTrueColorTerminal guesses whether the terminal supports RGB colors.
+func isTrueColorTerminal(c Capabilities) bool {
+	switch strings.ToLower(c.Name) {
+	case "kitty", "wezterm", "iterm2", "foot", "contour", "alacritty", "ghostty", "mintty", "konsole", "xterm.js":
+		return true
+	}
+	switch c.Type {
+	case 65: // VTE based terminals, such as GNOME Terminal
+		return c.Firmware >= 3600
+	case 77: // mintty
+		return true
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return true
+	}
+	return false
+}
+
+// parseParams parses numeric parameters separated by ";". Empty and invalid
+// parameters are 0.
+func parseParams(p []byte) []int {
+	if len(p) == 0 {
+		return nil
+	}
+	parts := bytes.Split(p, []byte{';'})
+	params := make([]int, len(parts))
+	for i, s := range parts {
+		params[i], _ = strconv.Atoi(string(s))
+	}
+	return params
+}
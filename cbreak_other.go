@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package terminal
+
+import "errors"
+
+// makeCbreak is not supported on this platform.
+func makeCbreak(fd int) (restore func() error, err error) {
+	return nil, errors.New("can't make terminal cbreak")
+}
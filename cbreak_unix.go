@@ -0,0 +1,24 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// makeCbreak turns off echo and line buffering of terminal fd.
+func makeCbreak(fd int) (restore func() error, err error) {
+	old, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	cbreak := *old
+	cbreak.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON
+	cbreak.Cc[unix.VMIN] = 1
+	cbreak.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &cbreak); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return unix.IoctlSetTermios(fd, ioctlWriteTermios, old)
+	}, nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import "golang.org/x/sys/windows"
+
+// makeCbreak turns off echo and line input of console fd.
+func makeCbreak(fd int) (restore func() error, err error) {
+	h := windows.Handle(fd)
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return nil, err
+	}
+	cbreak := mode&^(windows.ENABLE_ECHO_INPUT|windows.ENABLE_LINE_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(h, cbreak); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return windows.SetConsoleMode(h, mode)
+	}, nil
+}
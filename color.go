@@ -0,0 +1,267 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type colorKind uint8
+
+const (
+	colorUnset colorKind = iota
+	colorDefault
+	colorIndexed
+	colorRGB
+)
+
+// Color is a foreground, background or underline color that can be either one
+// of the 16 named colors, an entry of the 256-color palette or an RGB value.
+//
+// The zero Color is "unset", which leaves the current color as is when used in
+// a Style. DefaultColor resets it to the terminal default instead.
+type Color struct {
+	kind    colorKind
+	index   uint8
+	r, g, b uint8
+}
+
+// DefaultColor is the terminal default foreground or background color.
+var DefaultColor = Color{kind: colorDefault}
+
+// Named colors matching FgBlack through FgHiWhite and BgBlack through
+// BgHiWhite.
+var (
+	Black     = Indexed(0)
+	Red       = Indexed(1)
+	Green     = Indexed(2)
+	Yellow    = Indexed(3)
+	Blue      = Indexed(4)
+	Magenta   = Indexed(5)
+	Cyan      = Indexed(6)
+	White     = Indexed(7)
+	HiBlack   = Indexed(8)
+	HiRed     = Indexed(9)
+	HiGreen   = Indexed(10)
+	HiYellow  = Indexed(11)
+	HiBlue    = Indexed(12)
+	HiMagenta = Indexed(13)
+	HiCyan    = Indexed(14)
+	HiWhite   = Indexed(15)
+)
+
+// Indexed returns n-th color of the 256-color palette, where 0–15 are the named
+// colors. Values of n are limited to 0–255.
+func Indexed(n int) Color {
+	return Color{kind: colorIndexed, index: uint8(clamp8(n))}
+}
+
+// RGB returns a true color. Components are limited to 0–255.
+func RGB(r, g, b int) Color {
+	return Color{kind: colorRGB, r: uint8(clamp8(r)), g: uint8(clamp8(g)), b: uint8(clamp8(b))}
+}
+
+// IsSet reports whether c is anything but the zero Color.
+func (c Color) IsSet() bool {
+	return c.kind != colorUnset
+}
+
+// Fg returns a sequence setting foreground color to c, or an empty string for an
+// unset Color.
+func (c Color) Fg() string {
+	return c.sequence(30)
+}
+
+// Bg returns a sequence setting background color to c, or an empty string for an
+// unset Color.
+func (c Color) Bg() string {
+	return c.sequence(40)
+}
+
+func (c Color) sequence(base int) string {
+	p := c.params(base)
+	if p == "" {
+		return ""
+	}
+	return CSI + p + "m"
+}
+
+// params returns SGR parameters setting the color, where base is 30 for
+// foreground, 40 for background and 50 for underline.
+func (c Color) params(base int) string {
+	switch c.kind {
+	case colorDefault:
+		return strconv.Itoa(base + 9)
+	case colorIndexed:
+		if c.index < 16 && base != 50 {
+			return strconv.Itoa(ansi16Param(base, int(c.index)))
+		}
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(c.index))
+	case colorRGB:
+		return strconv.Itoa(base+8) + ";2;" + strconv.Itoa(int(c.r)) + ";" + strconv.Itoa(int(c.g)) + ";" + strconv.Itoa(int(c.b))
+	}
+	return ""
+}
+
+// ParseColor parses a color from a string, typically coming from a
+// configuration file. The following forms are accepted:
+//
+//	#ff8800, #f80               hexadecimal RGB
+//	rgb(255, 136, 0)            RGB components from 0 to 255 or percentages
+//	hsl(32, 100%, 50%)          hue in degrees, saturation and lightness
+//	orange                      CSS named colors
+//	208                         256-color palette index
+//	default                     terminal default color
+//
+// Components out of range are limited to the nearest valid value. Note that CSS
+// names produce RGB colors, so "red" is #ff0000 rather than the terminal's own
+// Red.
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "":
+		return Color{}, errors.New("empty color")
+	case s == "default":
+		return DefaultColor, nil
+	case s[0] == '#':
+		h := s[1:]
+		if len(h) == 3 {
+			h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+		}
+		if len(h) == 6 {
+			if v, err := strconv.ParseUint(h, 16, 32); err == nil {
+				return RGB(int(v>>16), int(v>>8&0xff), int(v&0xff)), nil
+			}
+		}
+	case strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba("):
+		args, ok := colorFuncArgs(s)
+		if ok && (len(args) == 3 || len(args) == 4) {
+			var rgb [3]int
+			for i := range rgb {
+				v, err := parseComponent(args[i], 255)
+				if err != nil {
+					return Color{}, fmt.Errorf("invalid color %q: %w", s, err)
+				}
+				rgb[i] = int(math.Round(v))
+			}
+			return RGB(rgb[0], rgb[1], rgb[2]), nil
+		}
+	case strings.HasPrefix(s, "hsl(") || strings.HasPrefix(s, "hsla("):
+		args, ok := colorFuncArgs(s)
+		if ok && (len(args) == 3 || len(args) == 4) {
+			h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+			if err != nil {
+				return Color{}, fmt.Errorf("invalid color %q: %w", s, err)
+			}
+			sat, err := parseComponent(args[1], 1)
+			if err != nil {
+				return Color{}, fmt.Errorf("invalid color %q: %w", s, err)
+			}
+			l, err := parseComponent(args[2], 1)
+			if err != nil {
+				return Color{}, fmt.Errorf("invalid color %q: %w", s, err)
+			}
+			r, g, b := hslToRGB(h, sat, l)
+			return RGB(r, g, b), nil
+		}
+	default:
+		if v, ok := cssColors[s]; ok {
+			return RGB(int(v>>16), int(v>>8&0xff), int(v&0xff)), nil
+		}
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+			return Indexed(n), nil
+		}
+	}
+	return Color{}, fmt.Errorf("invalid color %q", s)
+}
+
+// colorFuncArgs splits arguments of "name(a, b, c)" or "name(a b c / d)".
+func colorFuncArgs(s string) ([]string, bool) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return nil, false
+	}
+	inner := strings.NewReplacer(",", " ", "/", " ").Replace(s[open+1 : len(s)-1])
+	return strings.Fields(inner), true
+}
+
+// parseComponent parses a number or a percentage of max.
+func parseComponent(s string, max float64) (float64, error) {
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		return v * max / 100, err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if max == 1 && v > 1 {
+		v /= 100 // Saturation and lightness written without "%"
+	}
+	return v, err
+}
+
+// hslToRGB converts hue in degrees, saturation and lightness from 0 to 1 to RGB
+// components.
+func hslToRGB(h, s, l float64) (r, g, b int) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = math.Max(0, math.Min(1, s))
+	l = math.Max(0, math.Min(1, l))
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var rf, gf, bf float64
+	switch {
+	case h < 60:
+		rf, gf, bf = c, x, 0
+	case h < 120:
+		rf, gf, bf = x, c, 0
+	case h < 180:
+		rf, gf, bf = 0, c, x
+	case h < 240:
+		rf, gf, bf = 0, x, c
+	case h < 300:
+		rf, gf, bf = x, 0, c
+	default:
+		rf, gf, bf = c, 0, x
+	}
+	return int(math.Round((rf + m) * 255)), int(math.Round((gf + m) * 255)), int(math.Round((bf + m) * 255))
+}
+
+// String returns the color in a form accepted by ParseColor: "#rrggbb" for RGB
+// colors, palette index for the named and 256-color palette colors, "default"
+// for DefaultColor and an empty string for an unset Color.
+func (c Color) String() string {
+	switch c.kind {
+	case colorDefault:
+		return "default"
+	case colorIndexed:
+		return strconv.Itoa(int(c.index))
+	case colorRGB:
+		return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+	}
+	return ""
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseColor, so that
+// colors can be read directly from JSON, TOML or YAML configuration files. An
+// empty text results in an unset Color.
+func (c *Color) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = Color{}
+		return nil
+	}
+	v, err := ParseColor(string(text))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
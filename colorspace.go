@@ -0,0 +1,221 @@
+package terminal
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RGB returns RGB components of the color. Palette colors are resolved using
+// Palette256, while DefaultColor and an unset Color, which actual values are
+// unknown, are reported as black.
+func (c Color) RGB() (r, g, b int) {
+	switch c.kind {
+	case colorIndexed:
+		return RGBFrom256(int(c.index))
+	case colorRGB:
+		return int(c.r), int(c.g), int(c.b)
+	}
+	return 0, 0, 0
+}
+
+// HSL returns a color from hue in degrees, saturation and lightness from 0 to
+// 1.
+func HSL(h, s, l float64) Color {
+	return RGB(hslToRGB(h, s, l))
+}
+
+// HSL returns hue in degrees (0–360), saturation and lightness (0–1) of the
+// color.
+func (c Color) HSL() (h, s, l float64) {
+	r, g, b := c.RGB()
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	hi := math.Max(rf, math.Max(gf, bf))
+	lo := math.Min(rf, math.Min(gf, bf))
+	l = (hi + lo) / 2
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case rf:
+		h = math.Mod((gf-bf)/d, 6)
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// OKLCH returns a color from the OKLCH color space, where lightness l is from 0
+// to 1, chroma c is from 0 to about 0.37 and hue h is in degrees. Unlike HSL,
+// colors with the same lightness in OKLCH look equally light, which makes it a
+// good choice for picking colors that differ only in hue.
+//
+// Colors that can't be displayed are brought into the RGB range by reducing
+// chroma.
+func OKLCH(l, c, h float64) Color {
+	rad := h * math.Pi / 180
+	r, g, b, ok := oklabToRGB(l, c*math.Cos(rad), c*math.Sin(rad))
+	if !ok {
+		lo, hi := 0.0, c
+		for i := 0; i < 16; i++ {
+			mid := (lo + hi) / 2
+			if _, _, _, ok := oklabToRGB(l, mid*math.Cos(rad), mid*math.Sin(rad)); ok {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		r, g, b, _ = oklabToRGB(l, lo*math.Cos(rad), lo*math.Sin(rad))
+	}
+	return RGB(r, g, b)
+}
+
+// OKLCH returns lightness (0–1), chroma and hue in degrees (0–360) of the color
+// in the OKLCH color space.
+func (c Color) OKLCH() (l, chroma, h float64) {
+	l, a, b := c.oklab()
+	chroma = math.Hypot(a, b)
+	h = math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return l, chroma, h
+}
+
+// Luminance returns the relative luminance of the color from 0 for black to 1
+// for white as defined by WCAG.
+func (c Color) Luminance() float64 {
+	r, g, b := c.RGB()
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// ContrastRatio returns WCAG contrast ratio of two colors, from 1 for the same
+// colors to 21 for black and white. Text is considered readable at 4.5 and
+// above.
+func ContrastRatio(a, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// Blend mixes two colors in the OKLab color space, which gives perceptually
+// even transitions. t = 0 returns a, t = 1 returns b.
+func Blend(a, b Color, t float64) Color {
+	t = math.Max(0, math.Min(1, t))
+	l1, a1, b1 := a.oklab()
+	l2, a2, b2 := b.oklab()
+	r, g, bl, _ := oklabToRGB(l1+(l2-l1)*t, a1+(a2-a1)*t, b1+(b2-b1)*t)
+	return RGB(r, g, bl)
+}
+
+// distinctLightness and distinctChroma are chosen for colors that read well on
+// both dark and light backgrounds.
+const (
+	distinctLightness = 0.7
+	distinctChroma    = 0.15
+	goldenAngle       = 137.50776405003785
+)
+
+// DistinctColors returns n colors of the same lightness with hues evenly
+// distributed around the color wheel.
+func DistinctColors(n int) []Color {
+	colors := make([]Color, 0, n)
+	for i := 0; i < n; i++ {
+		colors = append(colors, OKLCH(distinctLightness, distinctChroma, 30+float64(i)*360/float64(n)))
+	}
+	return colors
+}
+
+// DistinctColor returns i-th color of an endless sequence where every next hue
+// is as far as possible from the previous ones (by the golden angle). Use it
+// instead of DistinctColors when the number of colors is not known in advance.
+func DistinctColor(i int) Color {
+	return OKLCH(distinctLightness, distinctChroma, math.Mod(30+float64(i)*goldenAngle, 360))
+}
+
+// Gradient returns text with every character colored by a gradual transition
+// from one color to another. Spaces are left as is and don't take part in the
+// transition. Colors are reset to the default at the end.
+func Gradient(text string, from, to Color) string {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	if n == 0 {
+		return text
+	}
+	var b strings.Builder
+	b.Grow(len(text) + n*20)
+	i := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			t := 0.0
+			if n > 1 {
+				t = float64(i) / float64(n-1)
+			}
+			b.WriteString(Blend(from, to, t).Fg())
+			i++
+		}
+		var buf [utf8.UTFMax]byte
+		b.Write(buf[:utf8.EncodeRune(buf[:], r)])
+	}
+	b.WriteString(DefaultFg())
+	return b.String()
+}
+
+// linear converts an sRGB component to linear light.
+func linear(v int) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// gamma converts linear light to an sRGB component.
+func gamma(f float64) float64 {
+	if f <= 0.0031308 {
+		return 12.92 * f * 255
+	}
+	return (1.055*math.Pow(f, 1/2.4) - 0.055) * 255
+}
+
+// oklab converts the color to OKLab (https://bottosson.github.io/posts/oklab/).
+func (c Color) oklab() (l, a, b float64) {
+	r8, g8, b8 := c.RGB()
+	r, g, bl := linear(r8), linear(g8), linear(b8)
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+	return 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc,
+		1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc,
+		0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+}
+
+// oklabToRGB converts OKLab to RGB components and reports whether the color is
+// within the RGB range.
+func oklabToRGB(l, a, b float64) (r, g, bl int, ok bool) {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+	rf := gamma(4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc)
+	gf := gamma(-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc)
+	bf := gamma(-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc)
+	const eps = 0.5
+	ok = !math.IsNaN(rf+gf+bf) && rf > -eps && rf < 255+eps && gf > -eps && gf < 255+eps && bf > -eps && bf < 255+eps
+	return clamp8(int(math.Round(rf))), clamp8(int(math.Round(gf))), clamp8(int(math.Round(bf))), ok
+}
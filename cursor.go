@@ -0,0 +1,35 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// CursorPosition asks the terminal where the cursor is using "Device Status
+// Report" (CSI 6n) request. Coordinates start from (0,0) as the top left corner,
+// the same way as MoveToXY accepts them. The terminal is put into raw mode for
+// the time of the query, which is limited by DefaultQueryTimeout.
+func (t *Terminal) CursorPosition() (x, y int, err error) {
+	// ESC [ 6 n | Report cursor position as ESC [ <r> ; <c> R
+	reply, err := t.queryWithFallback(CSI+"6n", DefaultQueryTimeout, isCursorPositionReport)
+	if err != nil {
+		return 0, 0, err
+	}
+	params := bytes.Split(reply[2:len(reply)-1], []byte{';'})
+	row, err1 := strconv.Atoi(string(params[0]))
+	col, err2 := strconv.Atoi(string(params[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, errors.New("invalid cursor position report " + strconv.Quote(string(reply)))
+	}
+	return col - 1, row - 1, nil
+}
+
+// isCursorPositionReport reports whether seq is "CSI <r> ; <c> R".
+func isCursorPositionReport(seq []byte) bool {
+	if len(seq) < 6 || seq[1] != '[' || seq[len(seq)-1] != 'R' {
+		return false
+	}
+	params := bytes.Split(seq[2:len(seq)-1], []byte{';'})
+	return len(params) == 2 && len(params[0]) > 0 && len(params[1]) > 0
+}
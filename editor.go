@@ -0,0 +1,542 @@
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned when the user presses Ctrl+C while input is read
+// in raw mode, where Ctrl+C doesn't send the interrupt signal.
+var ErrInterrupted = errors.New("interrupted")
+
+// DefaultHistoryLimit is the number of lines a LineEditor remembers unless
+// HistoryLimit says otherwise.
+const DefaultHistoryLimit = 1000
+
+// LineEditor reads lines typed by the user with editing keys known from
+// readline and shells:
+//
+//	Left, Right, Ctrl+B, Ctrl+F          move by character
+//	Ctrl+Left, Ctrl+Right, Alt+B, Alt+F  move by word
+//	Home, End, Ctrl+A, Ctrl+E            move to the start or end of the line
+//	Backspace, Delete, Ctrl+D            delete a character
+//	Ctrl+W, Alt+Backspace, Alt+D         kill a word before or after the cursor
+//	Ctrl+U, Ctrl+K                       kill to the start or end of the line
+//	Ctrl+Y                               yank the killed text
+//	Up, Down, Ctrl+P, Ctrl+N             walk the history
+//	Ctrl+R                               search the history backwards
+//	Tab                                  complete using Complete
+//	Ctrl+L                               clear the screen
+//
+// Ctrl+C makes ReadLine return ErrInterrupted and Ctrl+D on an empty line
+// io.EOF. If either input or output is not a terminal, lines are read without
+// editing.
+type LineEditor struct {
+	// Prompt is output before the line. It may contain colors and other
+	// sequences, such as FgGreen + "> " + Reset.
+	Prompt string
+	// Complete is called on Tab with the line and the cursor position as a byte
+	// offset in it, and returns candidates to replace line[start:pos] with. A
+	// single candidate is inserted, otherwise their common prefix is inserted and
+	// the second Tab lists them.
+	Complete func(line string, pos int) (start int, candidates []string)
+	// HistoryLimit is the number of lines remembered in the history, or
+	// DefaultHistoryLimit if 0.
+	HistoryLimit int
+
+	t           *Terminal
+	history     []string
+	historyFile string
+
+	line      []rune
+	pos       int
+	histIndex int    // Index of the shown history line, len(history) for the draft
+	draft     []rune // The line being edited before walking the history
+	killed    []rune
+	lastKill  bool
+	lastTab   bool
+	cursorRow int // Row of the cursor relative to the prompt after render
+
+	// Reverse incremental search
+	searching   bool
+	query       []rune
+	match       int // Index of the matching history line, or -1
+	searchStart []rune
+}
+
+// NewLineEditor returns a line editor reading from t's input and writing to
+// t.
+func NewLineEditor(t *Terminal, prompt string) *LineEditor {
+	return &LineEditor{t: t, Prompt: prompt}
+}
+
+// History returns remembered lines, the oldest first.
+func (e *LineEditor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// AddHistory adds line to the history, unless it's empty or repeats the last
+// line. ReadLine adds read lines itself.
+func (e *LineEditor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" || len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if over := len(e.history) - e.historyLimit(); over > 0 {
+		e.history = append([]string(nil), e.history[over:]...)
+	}
+	if e.historyFile != "" {
+		if f, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+			f.WriteString(line + "\n")
+			f.Close()
+		}
+	}
+}
+
+func (e *LineEditor) historyLimit() int {
+	if e.HistoryLimit > 0 {
+		return e.HistoryLimit
+	}
+	return DefaultHistoryLimit
+}
+
+// SetHistoryFile loads the history from the file at path, if it exists, and
+// appends every line added to the history to it from now on.
+func (e *LineEditor) SetHistoryFile(path string) error {
+	e.historyFile = ""
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	if over := len(lines) - e.historyLimit(); over > 0 {
+		lines = lines[over:]
+		// Keep the file from growing forever
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			return err
+		}
+	}
+	e.history = append(lines, e.history...)
+	e.historyFile = path
+	return nil
+}
+
+// ReadLine outputs the prompt and reads a line, putting the terminal into raw
+// mode for the time of reading unless it already is. The line is returned
+// without the line ending.
+func (e *LineEditor) ReadLine(ctx context.Context) (string, error) {
+	t := e.t
+	restore, err := t.rawInput()
+	if errors.Is(err, ErrNotTerminal) {
+		return e.readPlain(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	e.line, e.pos, e.draft = nil, 0, nil
+	e.histIndex = len(e.history)
+	e.cursorRow = 0
+	e.searching, e.lastKill, e.lastTab = false, false, false
+	e.render()
+	for {
+		ev, err := t.ReadEvent(ctx)
+		if err != nil {
+			e.finish("")
+			return "", err
+		}
+		switch ev := ev.(type) {
+		case PasteEvent:
+			e.stopSearch(true)
+			e.insert([]rune(strings.Map(func(r rune) rune {
+				if r == '\n' || r == '\t' {
+					return ' '
+				}
+				if unicode.IsControl(r) {
+					return -1
+				}
+				return r
+			}, ev.Text)))
+		case KeyEvent:
+			if ev.Action == KeyRelease {
+				continue
+			}
+			if line, done, err := e.handleKey(ev); done {
+				return line, err
+			}
+		}
+		e.render()
+	}
+}
+
+// readPlain reads a line without editing when input or output is not a
+// terminal.
+func (e *LineEditor) readPlain(ctx context.Context) (string, error) {
+	e.t.Print(e.Prompt)
+	line, err := e.t.readPlainLine(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(line), nil
+}
+
+// handleKey applies key to the line and reports whether reading is done.
+func (e *LineEditor) handleKey(key KeyEvent) (line string, done bool, err error) {
+	if e.searching && e.handleSearchKey(key) {
+		return "", false, nil
+	}
+	kill, tab := false, false
+	switch {
+	case key.Key == KeyEnter:
+		line = string(e.line)
+		e.finish("")
+		e.AddHistory(line)
+		return line, true, nil
+	case key.IsCtrl('c'):
+		e.finish("^C")
+		return "", true, ErrInterrupted
+	case key.IsCtrl('d') && len(e.line) == 0:
+		e.finish("")
+		return "", true, io.EOF
+	case key.Key == KeyLeft && key.Mod == 0, key.IsCtrl('b'):
+		if e.pos > 0 {
+			e.pos--
+		}
+	case key.Key == KeyRight && key.Mod == 0, key.IsCtrl('f'):
+		if e.pos < len(e.line) {
+			e.pos++
+		}
+	case key.Key == KeyLeft, key.Mod == ModAlt && key.Rune == 'b':
+		e.pos = e.wordStart()
+	case key.Key == KeyRight, key.Mod == ModAlt && key.Rune == 'f':
+		e.pos = e.wordEnd()
+	case key.Key == KeyHome, key.IsCtrl('a'):
+		e.pos = 0
+	case key.Key == KeyEnd, key.IsCtrl('e'):
+		e.pos = len(e.line)
+	case key.Key == KeyBackspace && key.Mod&ModAlt != 0, key.IsCtrl('w'):
+		e.kill(e.wordStart(), e.pos)
+		kill = true
+	case key.Key == KeyBackspace, key.IsCtrl('h'):
+		if e.pos > 0 {
+			e.line = append(e.line[:e.pos-1], e.line[e.pos:]...)
+			e.pos--
+		}
+	case key.Key == KeyDelete, key.IsCtrl('d'):
+		if e.pos < len(e.line) {
+			e.line = append(e.line[:e.pos], e.line[e.pos+1:]...)
+		}
+	case key.Mod == ModAlt && key.Rune == 'd':
+		e.kill(e.pos, e.wordEnd())
+		kill = true
+	case key.IsCtrl('u'):
+		e.kill(0, e.pos)
+		kill = true
+	case key.IsCtrl('k'):
+		e.kill(e.pos, len(e.line))
+		kill = true
+	case key.IsCtrl('y'):
+		e.insert(e.killed)
+	case key.Key == KeyUp, key.IsCtrl('p'):
+		e.showHistory(e.histIndex - 1)
+	case key.Key == KeyDown, key.IsCtrl('n'):
+		e.showHistory(e.histIndex + 1)
+	case key.IsCtrl('r'):
+		e.searching, e.query, e.match = true, nil, -1
+		e.searchStart = append([]rune(nil), e.line...)
+	case key.Key == KeyTab && key.Mod == 0:
+		e.complete()
+		tab = true
+	case key.IsCtrl('l'):
+		e.t.Print(MoveTopLeft() + EraseScreen())
+		e.cursorRow = 0
+	case key.Key == KeyRune && key.Mod&(ModCtrl|ModAlt) == 0:
+		e.insert([]rune{key.Rune})
+	}
+	e.lastKill, e.lastTab = kill, tab
+	return "", false, nil
+}
+
+// handleSearchKey handles key during the reverse incremental search and
+// reports whether it has been consumed. Keys not used by the search end it,
+// keeping the found line to be edited by the key.
+func (e *LineEditor) handleSearchKey(key KeyEvent) bool {
+	switch {
+	case key.IsCtrl('r'):
+		e.search(e.match - 1)
+	case key.Key == KeyBackspace:
+		if len(e.query) > 0 {
+			e.query = e.query[:len(e.query)-1]
+			e.search(len(e.history) - 1)
+		}
+	case key.Key == KeyEscape, key.IsCtrl('g'):
+		e.stopSearch(false)
+	case key.Key == KeyRune && key.Mod&(ModCtrl|ModAlt) == 0:
+		e.query = append(e.query, key.Rune)
+		from := e.match
+		if from < 0 {
+			from = len(e.history) - 1
+		}
+		e.search(from)
+	default:
+		e.stopSearch(true)
+		return false
+	}
+	return true
+}
+
+// search finds the query in the history, going back from index from.
+func (e *LineEditor) search(from int) {
+	if len(e.query) == 0 {
+		e.match = -1
+		return
+	}
+	q := string(e.query)
+	for i := from; i >= 0 && i < len(e.history); i-- {
+		if strings.Contains(e.history[i], q) {
+			e.match = i
+			return
+		}
+	}
+}
+
+// stopSearch ends the search, taking the found line if accept is true, or
+// returning to the line before the search.
+func (e *LineEditor) stopSearch(accept bool) {
+	if !e.searching {
+		return
+	}
+	e.searching = false
+	if accept && e.match >= 0 {
+		e.line, e.pos = e.searchMatch()
+		e.histIndex = e.match
+		return
+	}
+	e.line = e.searchStart
+	e.pos = len(e.line)
+}
+
+// searchMatch returns the line found by the search with the position of the
+// query in it.
+func (e *LineEditor) searchMatch() ([]rune, int) {
+	if e.match < 0 {
+		return e.searchStart, len(e.searchStart)
+	}
+	s := e.history[e.match]
+	i := strings.Index(s, string(e.query))
+	if i < 0 {
+		// The query has been extended past the last match
+		return []rune(s), len([]rune(s))
+	}
+	return []rune(s), len([]rune(s[:i]))
+}
+
+// searchFailed reports whether the query matches no history line, while the
+// last match is still shown.
+func (e *LineEditor) searchFailed() bool {
+	return len(e.query) > 0 && (e.match < 0 || !strings.Contains(e.history[e.match], string(e.query)))
+}
+
+// showHistory replaces the line with the history line at index i, where
+// len(history) is the line that has been typed before walking the history.
+func (e *LineEditor) showHistory(i int) {
+	if i < 0 || i > len(e.history) || i == e.histIndex {
+		return
+	}
+	if e.histIndex == len(e.history) {
+		e.draft = append([]rune(nil), e.line...)
+	}
+	e.histIndex = i
+	if i == len(e.history) {
+		e.line = e.draft
+	} else {
+		e.line = []rune(e.history[i])
+	}
+	e.pos = len(e.line)
+}
+
+// insert inserts runes at the cursor.
+func (e *LineEditor) insert(runes []rune) {
+	line := make([]rune, 0, len(e.line)+len(runes))
+	line = append(line, e.line[:e.pos]...)
+	line = append(line, runes...)
+	e.line = append(line, e.line[e.pos:]...)
+	e.pos += len(runes)
+}
+
+// kill removes line[from:to] remembering it for yanking. Consecutive kills are
+// collected together.
+func (e *LineEditor) kill(from, to int) {
+	if from >= to {
+		return
+	}
+	text := append([]rune(nil), e.line[from:to]...)
+	switch {
+	case !e.lastKill:
+		e.killed = text
+	case from < e.pos:
+		e.killed = append(text, e.killed...)
+	default:
+		e.killed = append(e.killed, text...)
+	}
+	e.line = append(e.line[:from], e.line[to:]...)
+	e.pos = from
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordStart returns the start of the word before the cursor.
+func (e *LineEditor) wordStart() int {
+	i := e.pos
+	for i > 0 && !isWordRune(e.line[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(e.line[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (e *LineEditor) wordEnd() int {
+	i := e.pos
+	for i < len(e.line) && !isWordRune(e.line[i]) {
+		i++
+	}
+	for i < len(e.line) && isWordRune(e.line[i]) {
+		i++
+	}
+	return i
+}
+
+// complete calls Complete and applies its result.
+func (e *LineEditor) complete() {
+	if e.Complete == nil {
+		return
+	}
+	line := string(e.line)
+	pos := len(string(e.line[:e.pos]))
+	start, candidates := e.Complete(line, pos)
+	if len(candidates) == 0 || start < 0 || start > pos {
+		return
+	}
+	insert := candidates[0]
+	if len(candidates) > 1 {
+		insert = commonPrefix(candidates)
+		if len(insert) <= pos-start {
+			if e.lastTab {
+				e.listCandidates(candidates)
+			}
+			return
+		}
+	}
+	e.line = []rune(line[:start] + insert + line[pos:])
+	e.pos = len([]rune(line[:start] + insert))
+}
+
+// listCandidates outputs completion candidates in columns below the line.
+func (e *LineEditor) listCandidates(candidates []string) {
+	w, _ := e.t.GetSize()
+	colWidth := 0
+	for _, c := range candidates {
+		if cw := StringWidth(c) + 2; cw > colWidth {
+			colWidth = cw
+		}
+	}
+	cols := w / colWidth
+	if cols < 1 {
+		cols = 1
+	}
+	var b strings.Builder
+	for i, c := range candidates {
+		if i%cols == 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(c)
+		if i%cols != cols-1 && i != len(candidates)-1 {
+			b.WriteString(strings.Repeat(" ", colWidth-StringWidth(c)))
+		}
+	}
+	pos := e.pos
+	e.finish(b.String())
+	e.pos = pos
+}
+
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// render redraws the prompt and the line, placing the cursor where it should
+// be. Lines longer than the terminal width wrap.
+func (e *LineEditor) render() {
+	t := e.t
+	w, _ := t.GetSize()
+	prompt, line, pos := e.Prompt, e.line, e.pos
+	if e.searching {
+		prefix := "(reverse-i-search)`"
+		if e.searchFailed() {
+			prefix = "(failed reverse-i-search)`"
+		}
+		prompt = prefix + string(e.query) + "': "
+		line, pos = e.searchMatch()
+	}
+	var b strings.Builder
+	if e.cursorRow > 0 {
+		b.WriteString(t.sequence("MoveByY", MoveByY(-e.cursorRow), -e.cursorRow))
+	}
+	b.WriteString("\r")
+	b.WriteString(t.sequence("EraseRestOfScreen", EraseRestOfScreen()))
+	b.WriteString(prompt)
+	b.WriteString(string(line))
+	total := StringWidth(prompt) + StringWidth(string(line))
+	if total > 0 && total%w == 0 {
+		// Move the cursor from past the last column to the next line
+		b.WriteString(" \b")
+	}
+	cursor := StringWidth(prompt) + StringWidth(string(line[:pos]))
+	row, endRow := cursor/w, total/w
+	if endRow > row {
+		b.WriteString(t.sequence("MoveByY", MoveByY(row-endRow), row-endRow))
+	}
+	b.WriteString("\r")
+	b.WriteString(t.sequence("MoveByX", MoveByX(cursor%w), cursor%w))
+	t.Print(b.String())
+	e.cursorRow = row
+}
+
+// finish moves the cursor past the end of the line, outputs s and starts a new
+// line.
+func (e *LineEditor) finish(s string) {
+	e.stopSearch(true)
+	e.pos = len(e.line)
+	e.render()
+	e.t.Print(s + "\r\n")
+	e.cursorRow = 0
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"github.com/zzwx/terminal"
 	"io"
-	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -91,19 +90,18 @@ func outputToTerminal(term *terminal.Terminal, data *dataWrap) {
 	lastLastIsNewLine = lnl
 }
 
-type rgb struct{ r, g, b int }
-
-var fgColors = make(map[int]rgb)
+var fgColors = make(map[int]terminal.Color)
 var fgColorsMu sync.Mutex
-var randSource = rand.New(rand.NewSource(50))
 
-func allocateFgColor(proc int) rgb {
+// allocateFgColor returns a color for the process, which hue is as far as
+// possible from the colors of the processes started before.
+func allocateFgColor(proc int) terminal.Color {
 	fgColorsMu.Lock()
 	defer fgColorsMu.Unlock()
 	if c, ok := fgColors[proc]; ok {
 		return c
 	}
-	c := rgb{50 + randSource.Intn(205), 50 + randSource.Intn(205), 50 + randSource.Intn(205)}
+	c := terminal.DistinctColor(len(fgColors))
 	fgColors[proc] = c
 	return c
 }
@@ -172,14 +170,17 @@ func Cmd(alias string, cmd *exec.Cmd) error {
 	wg.Add(3)
 	fg := allocateFgColor(cmd.Process.Pid)
 
+	prefix := terminal.Style{Fg: fg}.Render(base)
+	errDelimiter := terminal.Style{Fg: terminal.Red}.Render(" | ")
+
 	go func() {
 		defer wg.Done()
-		ioCopy(terminal.FgRGB(fg.r, fg.g, fg.b)+base+terminal.Reset, " | ", chStdOut, stdout)
+		ioCopy(prefix, " | ", chStdOut, stdout)
 	}()
 
 	go func() {
 		defer wg.Done()
-		ioCopy(terminal.FgRGB(fg.r, fg.g, fg.b)+base+terminal.Reset, terminal.FgRed+" | "+terminal.Reset, chStdErr, stderr)
+		ioCopy(prefix, errDelimiter, chStdErr, stderr)
 	}()
 
 	waitErr := make(chan error)
@@ -193,7 +194,7 @@ func Cmd(alias string, cmd *exec.Cmd) error {
 	if err != nil {
 		//output, _ := cmd.CombinedOutput()
 		//fmt.Println("error: " + err.Error())
-		ioCopy(terminal.FgRGB(fg.r, fg.g, fg.b)+base+terminal.Reset, terminal.FgRed+" | "+terminal.Reset, chStdErr,
+		ioCopy(prefix, errDelimiter, chStdErr,
 			strings.NewReader(fmt.Sprintf("%v\n%v\n", strings.Join(cmd.Args, " "), err)))
 	}
 	wg.Wait()
@@ -0,0 +1,373 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxPendingSequence limits how many bytes of an unfinished escape sequence are
+// held back waiting for the rest of it to arrive with the next Write.
+const maxPendingSequence = 256
+
+// filterWriter is a writer stage that processes escape sequences passing
+// through it on the fly:
+//
+// SGR ("CSI ... m") color sequences are rewritten to the nearest ones supported
+// by the color profile.
+//
+// SGR sequences are dropped entirely unless style is set.
+//
+// All other sequences (cursor movement, erasing, title, etc.) are dropped unless
+// controls is set.
+//
+// Sequences split between several writes are held back until they are complete.
+type filterWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	profile  ColorProfile
+	style    bool
+	controls bool
+	pending  []byte
+}
+
+func (f *filterWriter) Profile() ColorProfile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.style {
+		return NoColor
+	}
+	return f.profile
+}
+
+// Set changes the filtering rules for upcoming writes.
+func (f *filterWriter) Set(profile ColorProfile, style, controls bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.profile = profile
+	f.style = style
+	f.controls = controls
+}
+
+// SetWriter changes the writer the output goes to, flushing an incomplete
+// escape sequence to the previous one first.
+func (f *filterWriter) SetWriter(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_ = f.flush()
+	f.w = w
+}
+
+func (f *filterWriter) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.profile >= TrueColor && f.style && f.controls && len(f.pending) == 0 {
+		return f.w.Write(p)
+	}
+	n = len(p)
+	if len(f.pending) > 0 {
+		p = append(f.pending, p...)
+		f.pending = nil
+	}
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); {
+		if p[i] != ESC[0] {
+			j := bytes.IndexByte(p[i:], ESC[0])
+			if j < 0 {
+				out = append(out, p[i:]...)
+				break
+			}
+			out = append(out, p[i:i+j]...)
+			i += j
+			continue
+		}
+		l, complete := sequenceLen(p[i:])
+		if !complete {
+			if len(p)-i <= maxPendingSequence {
+				f.pending = append([]byte(nil), p[i:]...)
+				break
+			}
+			l = len(p) - i // Give up waiting for the rest of it
+		}
+		var seq []byte
+		out, seq = appendControls(out, p[i:i+l])
+		out = f.appendSequence(out, seq)
+		i += l
+	}
+	if len(out) > 0 {
+		if _, err = f.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// appendSequence appends escape sequence seq to out according to the filtering
+// rules.
+func (f *filterWriter) appendSequence(out []byte, seq []byte) []byte {
+	if isSGR(seq) {
+		switch {
+		case !f.style:
+			return out
+		case f.profile >= TrueColor:
+			return append(out, seq...)
+		}
+		return append(out, convertSGR(string(seq[2:len(seq)-1]), f.profile)...)
+	}
+	if !f.controls {
+		return out
+	}
+	return append(out, seq...)
+}
+
+// Flush writes out an incomplete escape sequence held back by Write as is.
+func (f *filterWriter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flush()
+}
+
+// flush is Flush with f.mu held.
+func (f *filterWriter) flush() error {
+	if len(f.pending) == 0 {
+		return nil
+	}
+	var err error
+	if f.controls {
+		_, err = f.w.Write(f.pending)
+	}
+	f.pending = nil
+	return err
+}
+
+// sequenceLen returns the length of an escape sequence starting at p[0] == ESC
+// and whether it is complete.
+func sequenceLen(p []byte) (n int, complete bool) {
+	if len(p) < 2 {
+		return 0, false
+	}
+	switch p[1] {
+	case '[': // CSI
+		end := csiEnd(p[2:])
+		if end < 0 {
+			return 0, false
+		}
+		return 2 + end + 1, true
+	case ']', 'P', 'X', '^', '_': // OSC, DCS, SOS, PM and APC strings
+		for i := 2; i < len(p); i++ {
+			switch p[i] {
+			case 0x07: // BEL
+				return i + 1, true
+			case ESC[0]:
+				if i+1 == len(p) {
+					return 0, false
+				}
+				if p[i+1] == '\\' { // ST
+					return i + 2, true
+				}
+				return i, true // Broken by another sequence
+			}
+		}
+		return 0, false
+	}
+	i := 1
+	for i < len(p) && p[i] >= 0x20 && p[i] <= 0x2f { // Intermediate bytes
+		i++
+	}
+	if i == len(p) {
+		return 0, false
+	}
+	return i + 1, true
+}
+
+// csiEnd returns the index of the final byte of a control sequence which
+// parameters start at p[0], or -1 if the sequence is incomplete. C0 control
+// characters other than ESC, CAN and SUB are executed by terminals without
+// breaking the sequence, see ECMA-48 5.5, so they are skipped. The index of
+// anything else that breaks the sequence is returned as if it was the final
+// byte.
+func csiEnd(p []byte) int {
+	for i, b := range p {
+		switch {
+		case b >= 0x20 && b <= 0x3f: // Parameter and intermediate bytes
+		case isEmbeddedControl(b):
+		default: // Final byte 0x40-0x7e or anything that breaks the sequence
+			return i
+		}
+	}
+	return -1
+}
+
+// isEmbeddedControl reports whether b is a C0 control character which doesn't
+// break a control sequence it appears in.
+func isEmbeddedControl(b byte) bool {
+	return b < 0x20 && b != ESC[0] && b != 0x18 && b != 0x1a
+}
+
+// appendControls appends C0 control characters embedded in control sequence
+// seq to out, returning seq without them.
+func appendControls(out []byte, seq []byte) ([]byte, []byte) {
+	if len(seq) < 3 || seq[1] != '[' {
+		return out, seq
+	}
+	var rest []byte // Set once there is a control character
+	for i, b := range seq[2 : len(seq)-1] {
+		switch {
+		case b >= 0x20:
+			if rest != nil {
+				rest = append(rest, b)
+			}
+			continue
+		case rest == nil:
+			rest = append([]byte(nil), seq[:2+i]...)
+		}
+		out = append(out, b)
+	}
+	if rest == nil {
+		return out, seq
+	}
+	return out, append(rest, seq[len(seq)-1])
+}
+
+// isSGR reports whether seq is a "Select Graphic Rendition" sequence. Private
+// sequences ending with "m", such as "CSI > 4 ; 1 m", are not.
+func isSGR(seq []byte) bool {
+	if len(seq) < 3 || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return false
+	}
+	return len(seq) == 3 || seq[2] >= '0' && seq[2] <= ';'
+}
+
+// extColor is an extended color (38, 48 or 58) parsed from SGR parameters.
+type extColor struct {
+	rgb     bool
+	index   int
+	r, g, b int
+}
+
+// convertSGR returns an SGR sequence built of params with colors converted to
+// the profile, or an empty string if nothing is left of it.
+func convertSGR(params string, p ColorProfile) string {
+	if params == "" {
+		return CSI + "m"
+	}
+	in := strings.Split(params, ";")
+	out := make([]string, 0, len(in))
+	for i := 0; i < len(in); i++ {
+		tok := in[i]
+		if sub := strings.Split(tok, ":"); len(sub) > 1 {
+			switch sub[0] {
+			case "38", "48", "58":
+				if c, _, ok := parseExtColor(sub[1:], true); ok {
+					out = appendColor(out, sub[0], c, p)
+				} else if p > NoColor {
+					out = append(out, tok)
+				}
+				continue
+			}
+			out = append(out, tok)
+			continue
+		}
+		v, _ := strconv.Atoi(tok)
+		switch {
+		case v == 38 || v == 48 || v == 58:
+			c, used, ok := parseExtColor(in[i+1:], false)
+			if ok {
+				out = appendColor(out, tok, c, p)
+			} else if p > NoColor {
+				out = append(out, in[i:i+1+used]...)
+			}
+			i += used
+		case p == NoColor && isColorParam(v):
+		default:
+			out = append(out, tok)
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return CSI + strings.Join(out, ";") + "m"
+}
+
+// parseExtColor parses arguments following 38, 48 or 58 and reports how many of
+// them were used. Colon separated form may contain the color space identifier
+// before RGB components.
+func parseExtColor(args []string, colon bool) (c extColor, used int, ok bool) {
+	if len(args) == 0 {
+		return c, 0, false
+	}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return c, len(args), false
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 || n > 255 {
+			return c, 2, false
+		}
+		return extColor{index: n}, 2, true
+	case "2":
+		rgb := args[1:]
+		if colon && len(rgb) > 3 {
+			rgb = rgb[1:] // Skip color space identifier
+		}
+		if len(rgb) < 3 {
+			return c, len(args), false
+		}
+		r, err1 := strconv.Atoi(rgb[0])
+		g, err2 := strconv.Atoi(rgb[1])
+		b, err3 := strconv.Atoi(rgb[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			return c, 4, false
+		}
+		return extColor{rgb: true, r: clamp8(r), g: clamp8(g), b: clamp8(b)}, 4, true
+	}
+	return c, 0, false
+}
+
+// appendColor appends kind (38, 48 or 58) color c converted to profile p.
+func appendColor(out []string, kind string, c extColor, p ColorProfile) []string {
+	switch p {
+	case NoColor:
+		return out
+	case ANSI16:
+		n := c.index
+		if c.rgb {
+			n = RGBTo16(c.r, c.g, c.b)
+		} else if n >= 16 {
+			n = RGBTo16(RGBFrom256(n))
+		}
+		switch kind {
+		case "38":
+			return append(out, strconv.Itoa(ansi16Param(30, n)))
+		case "48":
+			return append(out, strconv.Itoa(ansi16Param(40, n)))
+		}
+		return append(out, kind, "5", strconv.Itoa(n))
+	case ANSI256:
+		if c.rgb {
+			return append(out, kind, "5", strconv.Itoa(RGBTo256(c.r, c.g, c.b)))
+		}
+		return append(out, kind, "5", strconv.Itoa(c.index))
+	}
+	if c.rgb {
+		return append(out, kind, "2", strconv.Itoa(c.r), strconv.Itoa(c.g), strconv.Itoa(c.b))
+	}
+	return append(out, kind, "5", strconv.Itoa(c.index))
+}
+
+// ansi16Param returns SGR parameter for the named color n, where base is 30 for
+// foreground and 40 for background.
+func ansi16Param(base, n int) int {
+	if n < 8 {
+		return base + n
+	}
+	return base + 60 + n - 8
+}
+
+// isColorParam reports whether SGR parameter v sets or resets a color.
+func isColorParam(v int) bool {
+	return v >= 30 && v <= 49 || v == 59 || v >= 90 && v <= 97 || v >= 100 && v <= 107
+}
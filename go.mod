@@ -4,7 +4,6 @@ go 1.16
 
 require (
 	github.com/mattn/go-colorable v0.1.11
-	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
 	golang.org/x/sys v0.0.0-20211007075335-d3039528d8ac
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 )
@@ -0,0 +1,598 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultEscapeTimeout is how long a lone ESC byte waits for the rest of an
+// escape sequence before being reported as the Escape key. Terminals send
+// sequences at once, so only a slow network connection may need more.
+const DefaultEscapeTimeout = 50 * time.Millisecond
+
+// SetEscapeTimeout sets how long ESC waits for the following bytes to tell the
+// Escape key from Alt pressed with another key or a sequence, such as
+// "ESC [ A" sent for the Up key. DefaultEscapeTimeout is used when d is 0.
+func (t *Terminal) SetEscapeTimeout(d time.Duration) {
+	atomic.StoreInt64(&t.escTimeout, int64(d))
+}
+
+// ErrInputStopped is returned by ReadEvent and ReadKey waiting for input when
+// StopInput is called.
+var ErrInputStopped = errors.New("input stopped")
+
+// stopCheckInterval is how often reading the input checks whether it has been
+// stopped while there is nothing to read.
+const stopCheckInterval = 50 * time.Millisecond
+
+func (t *Terminal) escapeTimeout() time.Duration {
+	if d := time.Duration(atomic.LoadInt64(&t.escTimeout)); d > 0 {
+		return d
+	}
+	return DefaultEscapeTimeout
+}
+
+// Event is an input event read by ReadEvent: KeyEvent, MouseEvent,
+// PasteEvent, FocusEvent or ResizeEvent.
+type Event interface {
+	isEvent()
+}
+
+func (KeyEvent) isEvent() {}
+
+// FocusEvent reports that the terminal window has gained or lost focus while
+// SetFocusReporting is on.
+type FocusEvent struct {
+	Focused bool
+}
+
+func (FocusEvent) isEvent() {}
+
+// ReadEvent waits for an input event and decodes it. The terminal should be in
+// raw mode, see SetRaw, otherwise input is echoed and arrives only after Enter.
+//
+// The first call of ReadEvent, Events, ReadKey or Keys starts reading the
+// input, which continues in the background until it ends or StopInput is
+// called. The terminal owns the input until then, so other ways of reading it,
+// such as a bufio.Reader on os.Stdin or a child process, should not be mixed
+// with them. Replies to queries, such as CursorPosition, are still delivered to
+// the queries. The error is ctx.Err() if ctx is done first, io.EOF or the read
+// error once the input ends, or ErrInputStopped.
+func (t *Terminal) ReadEvent(ctx context.Context) (Event, error) {
+	r := t.input()
+	select {
+	case ev, ok := <-r.events:
+		if !ok {
+			return nil, r.err
+		}
+		return ev, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Events returns a channel of input events, which is closed when the input
+// ends. It is the same channel ReadEvent reads from, so every event is
+// delivered to only one of them.
+func (t *Terminal) Events() <-chan Event {
+	return t.input().events
+}
+
+// ReadKey waits for a key press the same way ReadEvent does it, discarding
+// other events.
+func (t *Terminal) ReadKey(ctx context.Context) (KeyEvent, error) {
+	for {
+		ev, err := t.ReadEvent(ctx)
+		if err != nil {
+			return KeyEvent{}, err
+		}
+		if key, ok := ev.(KeyEvent); ok {
+			return key, nil
+		}
+	}
+}
+
+// Keys returns a channel of key presses, which is closed when the input ends.
+// Once called, it takes all the events, discarding those which are not keys.
+func (t *Terminal) Keys() <-chan KeyEvent {
+	r := t.input()
+	r.keysOnce.Do(func() {
+		r.keys = make(chan KeyEvent)
+		go func() {
+			for ev := range r.events {
+				if key, ok := ev.(KeyEvent); ok {
+					select {
+					case r.keys <- key:
+					case <-r.stop:
+					}
+				}
+			}
+			close(r.keys)
+		}()
+	})
+	return r.keys
+}
+
+// StopInput stops reading the input in the background started by ReadEvent,
+// Events, ReadKey or Keys, handing the input back to other ways of reading it.
+// Channels returned by Events and Keys are closed, and events which haven't
+// been received are discarded. Reading starts again with the next call of
+// them.
+//
+// Waiting for input is not supported for readers other than files, such as
+// those of NewTerminalFromStreams, so a read in progress then still completes
+// and what it has read is discarded.
+func (t *Terminal) StopInput() {
+	t.inputMu.Lock()
+	r := t.reader
+	t.reader = nil
+	t.inputMu.Unlock()
+	if r == nil {
+		return
+	}
+	close(r.stop)
+	if r.stoppable {
+		<-r.done
+	}
+}
+
+// inputReader decodes the input into events in the background.
+type inputReader struct {
+	t       *Terminal
+	events  chan Event
+	replies chan []byte
+	// waiting is the number of queries waiting for replies, which is when
+	// sequences that aren't keys are passed to replies.
+	waiting int32
+	// err is set before closing events and replies.
+	err error
+	// resize receives the window size to be delivered as ResizeEvent.
+	resize chan Size
+	// stop is closed by StopInput. done is closed once the input is no longer
+	// read, which is right away if stoppable, or after a read in progress
+	// otherwise.
+	stop      chan struct{}
+	done      chan struct{}
+	stoppable bool
+
+	keysOnce sync.Once
+	keys     chan KeyEvent // Set by Keys
+
+	// paste collects text between bracketed paste start and end marks while
+	// pasting is true.
+	paste     []byte
+	pasting   bool
+	truncated bool
+}
+
+// input returns the input reader, starting it on the first call.
+func (t *Terminal) input() *inputReader {
+	w := t.resizeWatcher()
+	t.inputMu.Lock()
+	defer t.inputMu.Unlock()
+	if t.reader == nil {
+		in := t.source()
+		t.reader = &inputReader{
+			t:         t,
+			events:    make(chan Event, 64),
+			replies:   make(chan []byte, 16),
+			resize:    make(chan Size, 1),
+			stop:      make(chan struct{}),
+			done:      make(chan struct{}),
+			stoppable: canWait(in),
+		}
+		// Resizes are delivered along with the input while it's read
+		w.mu.Lock()
+		w.acquire()
+		w.mu.Unlock()
+		go t.reader.run(in)
+	}
+	return t.reader
+}
+
+// activeInput returns the input reader if it has been started.
+func (t *Terminal) activeInput() *inputReader {
+	t.inputMu.Lock()
+	defer t.inputMu.Unlock()
+	return t.reader
+}
+
+// canWait reports whether in can be waited for with waitReadable.
+func canWait(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return false
+	}
+	_, err := waitReadable(f, 0)
+	return err == nil
+}
+
+// wait waits until in has data to read, reporting false if the reader is
+// stopped first.
+func (r *inputReader) wait(in io.Reader) bool {
+	for {
+		select {
+		case <-r.stop:
+			return false
+		default:
+		}
+		if !r.stoppable {
+			return true
+		}
+		ok, err := waitReadable(in.(*os.File), stopCheckInterval)
+		if ok || err != nil {
+			return true
+		}
+	}
+}
+
+func (r *inputReader) run(in io.Reader) {
+	defer func() {
+		w := r.t.resizeWatcher()
+		w.mu.Lock()
+		w.release()
+		w.mu.Unlock()
+	}()
+	chunks := make(chan []byte)
+	var readErr error
+	go func() {
+		defer close(r.done)
+		buf := make([]byte, 1024)
+		for r.wait(in) {
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- append([]byte(nil), buf[:n]...):
+				case <-r.stop:
+					return
+				}
+			}
+			if err != nil {
+				readErr = err
+				close(chunks)
+				return
+			}
+		}
+	}()
+
+	var pending []byte
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				r.process(pending, true)
+				r.err = readErr
+				close(r.events)
+				close(r.replies)
+				return
+			}
+			pending = r.process(append(pending, chunk...), false)
+		case <-timer.C:
+			pending = r.process(pending, true)
+		case size := <-r.resize:
+			r.send(ResizeEvent{size})
+			continue
+		case <-r.stop:
+			r.err = ErrInputStopped
+			close(r.events)
+			close(r.replies)
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if len(pending) > 0 {
+			// Waiting for the rest of a sequence or a character
+			timer.Reset(r.t.escapeTimeout())
+		}
+	}
+}
+
+// send delivers ev unless the reader is stopped first.
+func (r *inputReader) send(ev Event) {
+	select {
+	case r.events <- ev:
+	case <-r.stop:
+	}
+}
+
+// process delivers everything decoded from p and returns the incomplete rest.
+// When force is true, the rest is decoded as is, such as a lone ESC being the
+// Escape key.
+func (r *inputReader) process(p []byte, force bool) []byte {
+	for len(p) > 0 {
+		if r.pasting {
+			var done bool
+			if p, done = r.pasteText(p); !done {
+				break
+			}
+			continue
+		}
+		if p[0] == ESC[0] && atomic.LoadInt32(&r.waiting) > 0 {
+			if n, complete := sequenceLen(p); complete && isReply(p[:n]) {
+				select {
+				case r.replies <- append([]byte(nil), p[:n]...):
+				default: // Nobody has been reading them
+				}
+				p = p[n:]
+				continue
+			}
+		}
+		ev, n := decodeEvent(p, force)
+		if n == 0 {
+			break
+		}
+		switch ev.(type) {
+		case nil:
+		case pasteStart:
+			r.pasting = true
+		default:
+			r.send(ev)
+		}
+		p = p[n:]
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+// collect returns replies to a query, which has been counted in waiting, the
+// same way as Terminal.exchange does it.
+func (r *inputReader) collect(timeout time.Duration, last func(seq []byte) bool) ([][]byte, error) {
+	var seqs [][]byte
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case seq, ok := <-r.replies:
+			if !ok {
+				return seqs, r.err
+			}
+			seqs = append(seqs, seq)
+			if last(seq) {
+				return seqs, nil
+			}
+		case <-timer.C:
+			return seqs, ErrNoReply
+		}
+	}
+}
+
+// isReply reports whether complete escape sequence seq is likely a reply to a
+// query rather than a key. Cursor position reports "CSI <r> ; <c> R" are
+// preferred to Shift+F3 and alike "CSI 1 ; <m> R" sent by some terminals.
+func isReply(seq []byte) bool {
+	switch seq[1] {
+	case ']', 'P', 'X', '^', '_':
+		return true
+	case '[':
+		if seq[len(seq)-1] == 'R' {
+			return true
+		}
+		ev, _ := decodeEvent(seq, true)
+		return ev == nil
+	}
+	return false
+}
+
+// decodeEvent decodes an event at the start of p and returns the number of
+// bytes it takes. The event is nil for sequences that are not understood, which
+// should be skipped. n is 0 if p is incomplete and force is false.
+func decodeEvent(p []byte, force bool) (Event, int) {
+	c := p[0]
+	switch {
+	case c == ESC[0]:
+		if len(p) == 1 {
+			if force {
+				return KeyEvent{Key: KeyEscape}, 1
+			}
+			return nil, 0
+		}
+		switch p[1] {
+		case '[':
+			return decodeCSI(p, force)
+		case 'O':
+			return decodeSS3(p, force)
+		}
+		// Alt pressed with a key, including "ESC ESC [ A" for Alt+Up sent by some
+		// terminals
+		ev, n := decodeEvent(p[1:], force)
+		if key, ok := ev.(KeyEvent); ok {
+			key.Mod |= ModAlt
+			return key, n + 1
+		}
+		if n == 0 {
+			return nil, 0
+		}
+		return ev, n + 1
+	case c == '\r' || c == '\n':
+		return KeyEvent{Key: KeyEnter}, 1
+	case c == '\t':
+		return KeyEvent{Key: KeyTab}, 1
+	case c == 0x7f || c == 0x08:
+		return KeyEvent{Key: KeyBackspace}, 1
+	case c == 0:
+		return KeyEvent{Rune: ' ', Mod: ModCtrl}, 1
+	case c < 0x1b:
+		return KeyEvent{Rune: rune('a' + c - 1), Mod: ModCtrl}, 1
+	case c < 0x20:
+		// Ctrl+\, Ctrl+], Ctrl+^ and Ctrl+_
+		return KeyEvent{Rune: rune('\\' + c - 0x1c), Mod: ModCtrl}, 1
+	}
+	if !utf8.FullRune(p) {
+		if force {
+			return nil, len(p)
+		}
+		return nil, 0
+	}
+	r, size := utf8.DecodeRune(p)
+	if r == utf8.RuneError && size == 1 {
+		return nil, 1
+	}
+	return KeyEvent{Rune: r}, size
+}
+
+// finalKeys are keys reported by the final byte of "CSI 1 ; <m> X" and
+// "SS3 X" sequences.
+var finalKeys = map[byte]Key{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// tildeKeys are keys reported as "CSI <n> ; <m> ~".
+var tildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPageUp, 6: KeyPageDown, 7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5, 17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12, 25: KeyF13, 26: KeyF14, 28: KeyF15, 29: KeyF16, 31: KeyF17, 32: KeyF18, 33: KeyF19, 34: KeyF20,
+}
+
+// decodeCSI decodes keys and mouse reports sent as control sequences starting
+// with "ESC [".
+func decodeCSI(p []byte, force bool) (Event, int) {
+	incomplete := func() (Event, int) {
+		if force {
+			return KeyEvent{Rune: '[', Mod: ModAlt}, 2
+		}
+		return nil, 0
+	}
+	if len(p) > 2 && p[2] == '[' {
+		// ESC [ [ A to ESC [ [ E | F1 to F5 of the Linux console
+		if len(p) < 4 {
+			return incomplete()
+		}
+		if p[3] >= 'A' && p[3] <= 'E' {
+			return KeyEvent{Key: KeyF1 + Key(p[3]-'A')}, 4
+		}
+		return nil, 4
+	}
+	if len(p) > 2 && p[2] == 'M' {
+		// ESC [ M <b> <x> <y> | Mouse report without SGR mode
+		if len(p) < 6 {
+			return incomplete()
+		}
+		return decodeX10Mouse(p[3:6]), 6
+	}
+	for i := 2; i < len(p) && p[i] < 0x40; i++ {
+		if p[i] < 0x20 {
+			// Terminals don't send control characters within sequences, so
+			// it's a key pressed after ESC [, which is decoded on its own
+			return nil, i
+		}
+	}
+	end := csiEnd(p[2:])
+	if end < 0 {
+		return incomplete()
+	}
+	final := p[2+end]
+	if final < 0x40 || final > 0x7e {
+		// Broken by a control character, which is decoded on its own
+		return nil, 2 + end
+	}
+	n := 2 + end + 1
+	params := p[2 : n-1]
+	if len(params) > 0 && params[0] == '<' && (final == 'M' || final == 'm') {
+		// ESC [ < <b> ; <x> ; <y> M | SGR mouse report
+		return decodeSGRMouse(params[1:], final == 'm'), n
+	}
+	if len(params) > 0 && params[0] > ';' {
+		// Private sequences, such as replies to queries
+		return nil, n
+	}
+	ps := parseSubParams(params)
+	var mod Modifier
+	var action KeyAction
+	if len(ps) > 1 {
+		// <modifiers> or <modifiers> : <event type> of the kitty keyboard protocol
+		mod = modifierParam(ps[1][0])
+		if len(ps[1]) > 1 {
+			action = keyActionParam(ps[1][1])
+		}
+	}
+	switch final {
+	case 'u':
+		return decodeKittyKey(ps, mod, action), n
+	case '~':
+		if len(ps) > 0 && ps[0][0] == 200 {
+			// ESC [ 200 ~ | Start of bracketed paste
+			return pasteStart{}, n
+		}
+		if len(ps) > 0 {
+			if k, ok := tildeKeys[ps[0][0]]; ok {
+				return KeyEvent{Key: k, Mod: mod, Action: action}, n
+			}
+		}
+	case 'Z':
+		return KeyEvent{Key: KeyTab, Mod: ModShift, Action: action}, n
+	case 'I', 'O':
+		if len(params) == 0 {
+			// ESC [ I and ESC [ O | Focus in and out
+			return FocusEvent{Focused: final == 'I'}, n
+		}
+	default:
+		if k, ok := finalKeys[final]; ok && (len(ps) == 0 || ps[0][0] <= 1) {
+			return KeyEvent{Key: k, Mod: mod, Action: action}, n
+		}
+	}
+	return nil, n
+}
+
+// keypadRunes are characters of the keypad in application mode, sent as
+// "ESC O <x>".
+var keypadRunes = map[byte]rune{
+	'j': '*', 'k': '+', 'l': ',', 'm': '-', 'n': '.', 'o': '/', 'X': '=',
+	'p': '0', 'q': '1', 'r': '2', 's': '3', 't': '4', 'u': '5', 'v': '6', 'w': '7', 'x': '8', 'y': '9',
+}
+
+// decodeSS3 decodes keys sent as "ESC O <x>", optionally with the modifier
+// parameter before x, such as "ESC O 5 P" for Ctrl+F1.
+func decodeSS3(p []byte, force bool) (Event, int) {
+	i := 2
+	for i < len(p) && (p[i] >= '0' && p[i] <= '9' || p[i] == ';') {
+		i++
+	}
+	if i == len(p) {
+		if force {
+			return KeyEvent{Rune: 'O', Mod: ModAlt}, 2
+		}
+		return nil, 0
+	}
+	if p[i] < 0x40 {
+		// Broken by a control character
+		return KeyEvent{Rune: 'O', Mod: ModAlt}, 2
+	}
+	var mod Modifier
+	if ps := parseParams(p[2:i]); len(ps) > 0 {
+		mod = modifierParam(ps[len(ps)-1])
+	}
+	final := p[i]
+	if k, ok := finalKeys[final]; ok {
+		return KeyEvent{Key: k, Mod: mod}, i + 1
+	}
+	if final == 'M' {
+		return KeyEvent{Key: KeyEnter, Mod: mod}, i + 1
+	}
+	if r, ok := keypadRunes[final]; ok {
+		return KeyEvent{Rune: r, Mod: mod}, i + 1
+	}
+	return nil, i + 1
+}
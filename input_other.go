@@ -0,0 +1,15 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package terminal
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// waitReadable is not supported on this platform.
+func waitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	return false, errors.New("can't wait for input")
+}
@@ -0,0 +1,49 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package terminal
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitReadable waits until f has data to read or timeout expires. The file
+// descriptor is used through SyscallConn, which unlike f.Fd() leaves f
+// non-blocking and keeps it open until polling is done.
+func waitReadable(f *os.File, timeout time.Duration) (ready bool, err error) {
+	c, err := f.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+	cerr := c.Control(func(fd uintptr) {
+		ready, err = poll(int(fd), timeout)
+	})
+	if cerr != nil {
+		return false, cerr
+	}
+	return ready, err
+}
+
+// poll waits until fd has data to read or timeout expires.
+func poll(fd int, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		// Rounded up, so that a timeout under a millisecond still waits
+		n, err := unix.Poll(fds, int((remaining+time.Millisecond-1)/time.Millisecond))
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
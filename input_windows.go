@@ -0,0 +1,83 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import (
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	peekConsoleInput = kernel32Dll.NewProc("PeekConsoleInputW")
+	readConsoleInput = kernel32Dll.NewProc("ReadConsoleInputW")
+)
+
+const keyEvent = 0x0001 // KEY_EVENT
+
+// inputRecord is INPUT_RECORD with the event as KEY_EVENT_RECORD, which is
+// the largest member of the union.
+type inputRecord struct {
+	eventType       uint16
+	_               uint16
+	keyDown         int32
+	repeatCount     uint16
+	virtualKeyCode  uint16
+	virtualScanCode uint16
+	char            uint16
+	controlKeyState uint32
+}
+
+// waitReadable waits until f has data to read or timeout expires. The console
+// handle is also signalled by events which don't produce characters, such as
+// key releases, focus and window size changes, which are discarded, as reading
+// would block until a key is pressed.
+func waitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	h := windows.Handle(f.Fd())
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		ev, err := windows.WaitForSingleObject(h, uint32(remaining/time.Millisecond))
+		if err != nil {
+			return false, err
+		}
+		if ev != windows.WAIT_OBJECT_0 {
+			return false, nil
+		}
+		chars, err := discardConsoleEvents(h)
+		if err != nil || chars {
+			// A pipe or another handle which is not a console is readable once
+			// signalled
+			return true, nil
+		}
+	}
+}
+
+// discardConsoleEvents reports whether the pending input of console h has
+// characters to read, discarding the events before them otherwise.
+func discardConsoleEvents(h windows.Handle) (chars bool, err error) {
+	var records [16]inputRecord
+	var n uint32
+	r, _, err := peekConsoleInput.Call(uintptr(h), uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&n)))
+	if r == 0 {
+		return false, err
+	}
+	for _, rec := range records[:n] {
+		if rec.eventType == keyEvent && rec.keyDown != 0 && rec.char != 0 {
+			return true, nil
+		}
+	}
+	if n > 0 {
+		r, _, err = readConsoleInput.Call(uintptr(h), uintptr(unsafe.Pointer(&records[0])), uintptr(n), uintptr(unsafe.Pointer(&n)))
+		if r == 0 {
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -87,7 +87,6 @@ func main() {
 								fmt.Fprintf(writer, "%v %v", names, field.Type)
 							}
 							fmt.Fprintf(writer, ") *Terminal {\n")
-							fmt.Fprintf(writer, "\tt.Print(%v(", fn.Name)
 							names := ""
 							for _, field := range fn.Type.Params.List {
 								for _, name := range field.Names {
@@ -97,7 +96,13 @@ func main() {
 									names += name.Name
 								}
 							}
-							fmt.Fprintf(writer, "%v))\n", names)
+							// Sequences can be replaced with ones from terminfo, see
+							// Terminal.sequence.
+							if names == "" {
+								fmt.Fprintf(writer, "\tt.Print(t.sequence(%q, %v()))\n", fn.Name.Name, fn.Name)
+							} else {
+								fmt.Fprintf(writer, "\tt.Print(t.sequence(%q, %v(%v), %v))\n", fn.Name.Name, fn.Name, names, names)
+							}
 							fmt.Fprintf(writer, "\treturn t\n")
 							fmt.Fprintf(writer, "}\n")
 						}
@@ -0,0 +1,175 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Key identifies a key reported by the terminal. Keys producing characters,
+// including space and control characters typed with Ctrl, are reported as
+// KeyRune with the character in KeyEvent.Rune.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+)
+
+var keyNames = map[Key]string{
+	KeyEnter:     "Enter",
+	KeyTab:       "Tab",
+	KeyBackspace: "Backspace",
+	KeyEscape:    "Escape",
+	KeyUp:        "Up",
+	KeyDown:      "Down",
+	KeyRight:     "Right",
+	KeyLeft:      "Left",
+	KeyHome:      "Home",
+	KeyEnd:       "End",
+	KeyInsert:    "Insert",
+	KeyDelete:    "Delete",
+	KeyPageUp:    "PageUp",
+	KeyPageDown:  "PageDown",
+}
+
+func (k Key) String() string {
+	switch {
+	case k == KeyRune:
+		return "Rune"
+	case k >= KeyF1 && k <= KeyF20:
+		return "F" + strconv.Itoa(int(k-KeyF1)+1)
+	}
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return "Key(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Modifier is a set of modifier keys held while pressing a key.
+type Modifier int
+
+const (
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
+	// ModMeta is the Super, Windows or Command key, reported only by some
+	// terminals.
+	ModMeta
+)
+
+func (m Modifier) String() string {
+	var parts []string
+	if m&ModCtrl != 0 {
+		parts = append(parts, "Ctrl")
+	}
+	if m&ModAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if m&ModShift != 0 {
+		parts = append(parts, "Shift")
+	}
+	if m&ModMeta != 0 {
+		parts = append(parts, "Meta")
+	}
+	return strings.Join(parts, "+")
+}
+
+// modifierParam converts the modifier parameter of control sequences, such as
+// 5 in "CSI 1 ; 5 A" for Ctrl+Up, which is 1 plus the modifier bits.
+func modifierParam(p int) Modifier {
+	if p <= 1 {
+		return 0
+	}
+	return Modifier(p-1) & (ModShift | ModAlt | ModCtrl | ModMeta)
+}
+
+// KeyAction tells whether a key is pressed, repeated or released. Only
+// terminals supporting the kitty keyboard protocol report repeats and releases
+// and only with KeyboardReportEvents flag pushed, see PushKeyboardFlags.
+type KeyAction int
+
+const (
+	KeyPress KeyAction = iota
+	KeyRepeat
+	KeyRelease
+)
+
+func (a KeyAction) String() string {
+	switch a {
+	case KeyRepeat:
+		return "Repeat"
+	case KeyRelease:
+		return "Release"
+	}
+	return "Press"
+}
+
+// KeyEvent is a key press read from the terminal.
+type KeyEvent struct {
+	Key Key
+	// Rune is the character for KeyRune. Letters typed with Ctrl are lower case,
+	// for instance 'c' with ModCtrl for Ctrl+C.
+	Rune   rune
+	Mod    Modifier
+	Action KeyAction
+}
+
+// String returns a readable form of the key, such as "Ctrl+C", "Alt+Left" or
+// "x".
+func (e KeyEvent) String() string {
+	var name string
+	switch {
+	case e.Key != KeyRune:
+		name = e.Key.String()
+	case e.Rune == ' ':
+		name = "Space"
+	case e.Mod&ModCtrl != 0:
+		name = string(unicode.ToUpper(e.Rune))
+	default:
+		name = string(e.Rune)
+	}
+	if e.Mod != 0 {
+		return e.Mod.String() + "+" + name
+	}
+	return name
+}
+
+// IsCtrl reports whether e is Ctrl pressed with character r and no other
+// modifiers, for instance e.IsCtrl('c') for Ctrl+C.
+func (e KeyEvent) IsCtrl(r rune) bool {
+	return e.Key == KeyRune && e.Mod == ModCtrl && e.Rune == r && e.Action != KeyRelease
+}
@@ -0,0 +1,159 @@
+package terminal
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// KeyboardFlags are progressive enhancements of the kitty keyboard protocol,
+// which reports keys unambiguously, such as Ctrl+I apart from Tab, and is
+// supported by kitty, foot, WezTerm, Ghostty, Alacritty and others.
+type KeyboardFlags int
+
+const (
+	// KeyboardDisambiguate reports keys that can't be told apart otherwise,
+	// such as Ctrl+I, Alt+[ or Escape, as "CSI ... u" sequences.
+	KeyboardDisambiguate KeyboardFlags = 1 << iota
+	// KeyboardReportEvents reports repeats and releases of keys, see KeyAction.
+	KeyboardReportEvents
+	// KeyboardReportAlternates reports the shifted key along with the key.
+	KeyboardReportAlternates
+	// KeyboardReportAllKeys reports every key as a sequence, including Enter,
+	// Tab, Backspace and characters.
+	KeyboardReportAllKeys
+	// KeyboardReportText reports text the key produces along with the key.
+	KeyboardReportText
+)
+
+// PushKeyboardFlags turns on the kitty keyboard protocol enhancements, saving
+// the previous ones on the terminal's stack. Flags pushed this way are popped
+// by PopKeyboardFlags or automatically when raw mode is turned off with
+// SetRaw(false). Terminals not supporting the protocol ignore it, which can be
+// found out with QueryKeyboardFlags.
+func (t *Terminal) PushKeyboardFlags(flags KeyboardFlags) {
+	t.init()
+	t.modeMu.Lock()
+	// ESC [ > <flags> u | Push keyboard flags
+	t.Print(CSI + ">" + strconv.Itoa(int(flags)) + "u")
+	t.keyboardPushes++
+	t.modeMu.Unlock()
+	t.record("PushKeyboardFlags", nil)
+}
+
+// PopKeyboardFlags restores the kitty keyboard protocol enhancements which
+// were active before the last PushKeyboardFlags.
+func (t *Terminal) PopKeyboardFlags() {
+	t.modeMu.Lock()
+	defer t.modeMu.Unlock()
+	t.popKeyboardFlags(1)
+}
+
+// popKeyboardFlags pops up to n flags pushed with PushKeyboardFlags. t.modeMu
+// must be held.
+func (t *Terminal) popKeyboardFlags(n int) {
+	if n > t.keyboardPushes {
+		n = t.keyboardPushes
+	}
+	if n <= 0 {
+		return
+	}
+	// ESC [ < <n> u | Pop n entries of keyboard flags
+	t.Print(CSI + "<" + strconv.Itoa(n) + "u")
+	t.keyboardPushes -= n
+}
+
+// QueryKeyboardFlags asks the terminal for the active kitty keyboard protocol
+// enhancements. ErrNoReply means the terminal doesn't support the protocol.
+func (t *Terminal) QueryKeyboardFlags(timeout time.Duration) (KeyboardFlags, error) {
+	// ESC [ ? u | Report as ESC [ ? <flags> u
+	reply, err := t.queryWithFallback(CSI+"?u", timeout, func(seq []byte) bool {
+		return bytes.HasPrefix(seq, []byte(CSI+"?")) && seq[len(seq)-1] == 'u'
+	})
+	if err != nil {
+		return 0, err
+	}
+	flags, err := strconv.Atoi(string(reply[3 : len(reply)-1]))
+	if err != nil {
+		return 0, err
+	}
+	return KeyboardFlags(flags), nil
+}
+
+// kittyKeys are keys of the kitty keyboard protocol which codes are not
+// characters.
+var kittyKeys = map[int]Key{
+	13: KeyEnter, 9: KeyTab, 127: KeyBackspace, 8: KeyBackspace, 27: KeyEscape,
+	57414: KeyEnter, 57417: KeyLeft, 57418: KeyRight, 57419: KeyUp, 57420: KeyDown,
+	57421: KeyPageUp, 57422: KeyPageDown, 57423: KeyHome, 57424: KeyEnd, 57425: KeyInsert, 57426: KeyDelete,
+	57376: KeyF13, 57377: KeyF14, 57378: KeyF15, 57379: KeyF16, 57380: KeyF17, 57381: KeyF18, 57382: KeyF19, 57383: KeyF20,
+}
+
+// kittyKeypadRunes are characters of the keypad in the kitty keyboard
+// protocol.
+var kittyKeypadRunes = map[int]rune{
+	57399: '0', 57400: '1', 57401: '2', 57402: '3', 57403: '4', 57404: '5', 57405: '6', 57406: '7', 57407: '8', 57408: '9',
+	57409: '.', 57410: '/', 57411: '*', 57412: '-', 57413: '+', 57415: '=', 57416: ',',
+}
+
+// decodeKittyKey decodes "CSI <code>[:<shifted>[:<base>]] ; <mods>[:<event>]
+// ; <text> u" sequences of the kitty keyboard protocol, which are also sent
+// by xterm for some keys with modifyOtherKeys set. Modifier keys pressed on
+// their own, reported with KeyboardReportAllKeys, are skipped.
+func decodeKittyKey(ps [][]int, mod Modifier, action KeyAction) Event {
+	if len(ps) == 0 {
+		return nil
+	}
+	code := ps[0][0]
+	if k, ok := kittyKeys[code]; ok {
+		return KeyEvent{Key: k, Mod: mod, Action: action}
+	}
+	if r, ok := kittyKeypadRunes[code]; ok {
+		return KeyEvent{Rune: r, Mod: mod, Action: action}
+	}
+	if code < 0x20 || code >= 57344 && code <= 63743 || code > 0x10ffff {
+		// Control characters and other keys from the private use area
+		return nil
+	}
+	r := rune(code)
+	if len(ps[0]) > 1 && ps[0][1] != 0 && mod&ModShift != 0 {
+		// Shifted key is reported with KeyboardReportAlternates
+		r = rune(ps[0][1])
+		mod &^= ModShift
+	} else if mod&ModShift != 0 && mod&ModCtrl == 0 && unicode.IsLower(r) {
+		r = unicode.ToUpper(r)
+		mod &^= ModShift
+	}
+	return KeyEvent{Rune: r, Mod: mod, Action: action}
+}
+
+// keyActionParam converts the event type of the kitty keyboard protocol.
+func keyActionParam(p int) KeyAction {
+	switch p {
+	case 2:
+		return KeyRepeat
+	case 3:
+		return KeyRelease
+	}
+	return KeyPress
+}
+
+// parseSubParams parses numeric parameters separated by ";", each of which may
+// have sub-parameters separated by ":". Every parameter has at least one
+// value. Empty and invalid values are 0.
+func parseSubParams(p []byte) [][]int {
+	if len(p) == 0 {
+		return nil
+	}
+	parts := bytes.Split(p, []byte{';'})
+	params := make([][]int, len(parts))
+	for i, part := range parts {
+		subs := bytes.Split(part, []byte{':'})
+		params[i] = make([]int, len(subs))
+		for j, s := range subs {
+			params[i][j], _ = strconv.Atoi(string(s))
+		}
+	}
+	return params
+}
@@ -0,0 +1,84 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// lineReader reads the input line by line in the background when it's not a
+// terminal, such as when it's piped.
+type lineReader struct {
+	lines chan []byte
+	// err is set before closing lines.
+	err error
+}
+
+// lines returns the line reader, starting it on the first call.
+func (t *Terminal) lines() *lineReader {
+	t.init()
+	t.inputMu.Lock()
+	defer t.inputMu.Unlock()
+	if t.lineReader == nil {
+		t.lineReader = &lineReader{lines: make(chan []byte)}
+		go t.lineReader.run(t.source())
+	}
+	return t.lineReader
+}
+
+// readPlainLine reads a line as is, without the line ending, which is either
+// "\n" or "\r\n". The last line doesn't need to end with one. The error is
+// ctx.Err() if ctx is done first, or io.EOF or the read error once the input
+// ends.
+func (t *Terminal) readPlainLine(ctx context.Context) ([]byte, error) {
+	r := t.lines()
+	select {
+	case line, ok := <-r.lines:
+		if !ok {
+			return nil, r.err
+		}
+		return line, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *lineReader) run(in io.Reader) {
+	br := bufio.NewReader(in)
+	for {
+		var line []byte
+		var err error
+		for {
+			var chunk []byte
+			chunk, err = br.ReadSlice('\n')
+			line = appendWiping(line, chunk)
+			wipe(chunk) // The line may be a secret
+			if err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		if err == nil {
+			r.lines <- bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+			continue
+		}
+		if len(line) > 0 {
+			r.lines <- line
+		}
+		r.err = err
+		close(r.lines)
+		return
+	}
+}
+
+// appendWiping appends chunk to line like append, but wipes line when it has
+// to be moved to a larger array, so that no copy of a secret is left behind.
+func appendWiping(line, chunk []byte) []byte {
+	if len(line)+len(chunk) <= cap(line) {
+		return append(line, chunk...)
+	}
+	grown := make([]byte, len(line), 2*cap(line)+len(chunk))
+	copy(grown, line)
+	wipe(line)
+	return append(grown, chunk...)
+}
@@ -0,0 +1,197 @@
+package terminal
+
+import "strings"
+
+// Markup converts a string with style tags into a string with sequences.
+//
+// A tag is written as [fg:bg:flags], where every part is optional and may be
+// left empty to keep what the enclosing tag has set:
+//
+//	[red]              red text
+//	[red:white]        red on white
+//	[:blue]            blue background
+//	[::b]              bold
+//	[yellow::bu]       yellow bold and underlined
+//	[#ff8800]          RGB color
+//	[fg=208]           color 208 of the 256-color palette
+//	[bg=gold]          CSS named color background
+//	[-]                back to the style before the last tag
+//
+// Colors are the named colors (black, red, green, yellow, blue, magenta, cyan,
+// white and their "hi" variants, such as hired), "default" and "#rrggbb" or
+// "#rgb". Anything else accepted by ParseColor, such as 256-color palette
+// indexes, CSS names or "rgb(...)", has to be prefixed with "fg=" or "bg=",
+// which also sets the color regardless of its position in the tag. Setting a
+// color to "-" unsets it.
+//
+// Flags are b for bold, d for dim, i for italic, u for underline, l for blink,
+// r for reverse, h for hidden, s for strikethrough and o for overline. An
+// upper case flag turns the attribute off, and "-" turns all of them off.
+//
+// Tags are nested, so that [-] returns to the enclosing style. Anything left
+// open is closed at the end of the string, restoring only the attributes that
+// have been changed.
+//
+// "[[" outputs a literal "[". Square brackets that don't form a valid tag are
+// output as is, so "[WARN]", "[1/3]", "[42]" or "[gold]" don't need escaping.
+//
+// Markup doesn't know where the result is going to be output. Printing it using
+// a Terminal converts the colors to the terminal color profile or strips them
+// if the output is not a terminal, see Terminal.Mprintf.
+func Markup(s string) string {
+	var b strings.Builder
+	stack := []Style{{}}
+	var applied Style
+	for i := 0; i < len(s); {
+		if s[i] != '[' {
+			j := strings.IndexByte(s[i:], '[')
+			if j < 0 {
+				j = len(s) - i
+			}
+			b.WriteString(applied.Transition(stack[len(stack)-1]))
+			applied = stack[len(stack)-1]
+			b.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '[' {
+			b.WriteString(applied.Transition(stack[len(stack)-1]))
+			applied = stack[len(stack)-1]
+			b.WriteByte('[')
+			i += 2
+			continue
+		}
+		end := strings.IndexByte(s[i+1:], ']')
+		if end >= 0 {
+			tag := s[i+1 : i+1+end]
+			if tag == "-" {
+				if len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+				i += end + 2
+				continue
+			}
+			if style, ok := applyTag(stack[len(stack)-1], tag); ok {
+				stack = append(stack, style)
+				i += end + 2
+				continue
+			}
+		}
+		// Not a tag
+		b.WriteString(applied.Transition(stack[len(stack)-1]))
+		applied = stack[len(stack)-1]
+		b.WriteByte('[')
+		i++
+	}
+	b.WriteString(applied.Transition(Style{}))
+	return b.String()
+}
+
+// Mprintf formats according to a format specifier with Markup tags and outputs
+// the result. Only the format is parsed for tags, so arguments are output as
+// is, even if they contain square brackets.
+//
+//	t.Mprintf("[red::b]error[-]: file [cyan]%s[-] not found\n", name)
+func (t *Terminal) Mprintf(format string, a ...interface{}) (n int, err error) {
+	return t.Printf(Markup(format), a...)
+}
+
+// applyTag returns style base modified by tag or false if tag is not a valid
+// style tag.
+func applyTag(base Style, tag string) (Style, bool) {
+	parts := strings.Split(tag, ":")
+	if len(parts) > 3 || tag == "" {
+		return base, false
+	}
+	style := base
+	for i := 0; i < len(parts) && i < 2; i++ {
+		part := parts[i]
+		if part == "" {
+			continue
+		}
+		fg := i == 0
+		explicit := false
+		switch {
+		case strings.HasPrefix(part, "fg="):
+			fg, explicit, part = true, true, part[3:]
+		case strings.HasPrefix(part, "bg="):
+			fg, explicit, part = false, true, part[3:]
+		}
+		c, ok := parseMarkupColor(part, explicit)
+		if !ok {
+			return base, false
+		}
+		if fg {
+			style.Fg = c
+		} else {
+			style.Bg = c
+		}
+	}
+	if len(parts) > 2 {
+		for _, f := range parts[2] {
+			switch f {
+			case '-':
+				style = Style{Fg: style.Fg, Bg: style.Bg}
+			case 'b', 'B':
+				style.Bold = f == 'b'
+			case 'd', 'D':
+				style.Dim = f == 'd'
+			case 'i', 'I':
+				style.Italic = f == 'i'
+			case 'u':
+				style.Underline = UnderlineSingle
+			case 'U':
+				style.Underline = UnderlineNone
+			case 'l', 'L':
+				style.Blink = f == 'l'
+			case 'r', 'R':
+				style.Reverse = f == 'r'
+			case 'h', 'H':
+				style.Hidden = f == 'h'
+			case 's', 'S':
+				style.Strikethrough = f == 's'
+			case 'o', 'O':
+				style.Overline = f == 'o'
+			default:
+				return base, false
+			}
+		}
+	}
+	return style, true
+}
+
+var markupColors = map[string]Color{
+	"black":     Black,
+	"red":       Red,
+	"green":     Green,
+	"yellow":    Yellow,
+	"blue":      Blue,
+	"magenta":   Magenta,
+	"cyan":      Cyan,
+	"white":     White,
+	"hiblack":   HiBlack,
+	"hired":     HiRed,
+	"higreen":   HiGreen,
+	"hiyellow":  HiYellow,
+	"hiblue":    HiBlue,
+	"himagenta": HiMagenta,
+	"hicyan":    HiCyan,
+	"hiwhite":   HiWhite,
+	"default":   DefaultColor,
+	"-":         {},
+}
+
+// parseMarkupColor parses a color part of a Markup tag. Named colors are the
+// terminal's own. Other colors are parsed by ParseColor, but only "#" colors
+// unless the part is explicit, having the "fg=" or "bg=" prefix, so that
+// ordinary text in brackets, such as "[42]" or "[tan]", is not taken for a tag.
+func parseMarkupColor(s string, explicit bool) (Color, bool) {
+	if c, ok := markupColors[strings.ToLower(s)]; ok {
+		return c, true
+	}
+	if !explicit && !strings.HasPrefix(s, "#") {
+		return Color{}, false
+	}
+	c, err := ParseColor(s)
+	return c, err == nil
+}
@@ -0,0 +1,136 @@
+package terminal
+
+// MouseButton is a mouse button or a wheel direction.
+type MouseButton int
+
+const (
+	// MouseNone is reported for motion without buttons pressed and for
+	// releases when the terminal doesn't say which button was released.
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseBackward
+	MouseForward
+)
+
+func (b MouseButton) String() string {
+	switch b {
+	case MouseLeft:
+		return "Left"
+	case MouseMiddle:
+		return "Middle"
+	case MouseRight:
+		return "Right"
+	case MouseWheelUp:
+		return "WheelUp"
+	case MouseWheelDown:
+		return "WheelDown"
+	case MouseWheelLeft:
+		return "WheelLeft"
+	case MouseWheelRight:
+		return "WheelRight"
+	case MouseBackward:
+		return "Backward"
+	case MouseForward:
+		return "Forward"
+	}
+	return "None"
+}
+
+// MouseAction tells what happened to the mouse.
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	// MouseMotion is reported with SetMouseDrag while a button is held, and
+	// with SetMouseMotion also without buttons.
+	MouseMotion
+)
+
+func (a MouseAction) String() string {
+	switch a {
+	case MouseRelease:
+		return "Release"
+	case MouseMotion:
+		return "Motion"
+	}
+	return "Press"
+}
+
+// MouseEvent is a mouse report enabled with SetMouseButtons, SetMouseDrag or
+// SetMouseMotion. X and Y start from (0,0) as the top left corner, the same
+// way as MoveToXY accepts them. Wheel scrolling is reported as MousePress of
+// the wheel buttons.
+type MouseEvent struct {
+	X, Y   int
+	Button MouseButton
+	Action MouseAction
+	// Modifiers are held keys. Terminals often use some of them for their own
+	// purposes, such as Shift for selecting text.
+	Modifiers Modifier
+}
+
+func (MouseEvent) isEvent() {}
+
+// decodeSGRMouse decodes "CSI < <b> ; <x> ; <y> M" press and "... m" release
+// reports, which params are given without "<".
+func decodeSGRMouse(params []byte, release bool) Event {
+	ps := parseParams(params)
+	if len(ps) != 3 {
+		return nil
+	}
+	ev := mouseEvent(ps[0], ps[1]-1, ps[2]-1)
+	if release {
+		ev.Action = MouseRelease
+	}
+	return ev
+}
+
+// decodeX10Mouse decodes "CSI M <b> <x> <y>" reports used without
+// SetSGRMouse, where each value is a byte with 32 added. Coordinates over 222
+// can't be reported.
+func decodeX10Mouse(p []byte) Event {
+	ev := mouseEvent(int(p[0])-32, int(p[1])-33, int(p[2])-33)
+	if ev.Button == MouseNone && ev.Action == MousePress {
+		// Button 3 means release of any button
+		ev.Action = MouseRelease
+	}
+	return ev
+}
+
+// mouseEvent decodes button parameter b of mouse reports, where the lower two
+// bits are the button, 4, 8 and 16 are Shift, Alt and Ctrl, 32 is motion, and
+// 64 and 128 select wheel and extra buttons.
+func mouseEvent(b, x, y int) MouseEvent {
+	ev := MouseEvent{X: x, Y: y}
+	if b&4 != 0 {
+		ev.Modifiers |= ModShift
+	}
+	if b&8 != 0 {
+		ev.Modifiers |= ModAlt
+	}
+	if b&16 != 0 {
+		ev.Modifiers |= ModCtrl
+	}
+	if b&32 != 0 {
+		ev.Action = MouseMotion
+	}
+	n := b & 3
+	switch {
+	case b&128 != 0:
+		if n < 2 {
+			ev.Button = MouseBackward + MouseButton(n)
+		}
+	case b&64 != 0:
+		ev.Button = MouseWheelUp + MouseButton(n)
+	case n < 3:
+		ev.Button = MouseLeft + MouseButton(n)
+	}
+	return ev
+}
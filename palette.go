@@ -0,0 +1,112 @@
+package terminal
+
+// palette256 is the default xterm 256-color palette:
+//
+// 0–15 are the named colors, FgBlack through FgHiWhite, which actual values
+// are often redefined by terminal themes.
+//
+// 16–231 is a 6×6×6 color cube, where index = 16 + 36×r + 6×g + b for r, g, b
+// in 0–5.
+//
+// 232–255 is a gray ramp from almost black to almost white.
+var palette256 [256][3]int
+
+// cubeLevels are the component values of the 6x6x6 color cube in the 256-color
+// palette.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+func init() {
+	named := [16][3]int{
+		{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+		{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+		{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+		{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+	}
+	copy(palette256[:16], named[:])
+	for i := 0; i < 216; i++ {
+		palette256[16+i] = [3]int{cubeLevels[i/36], cubeLevels[(i/6)%6], cubeLevels[i%6]}
+	}
+	for i := 0; i < 24; i++ {
+		v := 8 + 10*i
+		palette256[232+i] = [3]int{v, v, v}
+	}
+}
+
+// Palette256 returns RGB values of the default xterm 256-color palette used by
+// Fg256 and Bg256. Entries 0–15 are the named colors as xterm shows them.
+func Palette256() [256][3]int {
+	return palette256
+}
+
+// RGBTo256 returns the index of the 256-color palette entry nearest to the RGB
+// color. Only the color cube and the gray ramp are considered, since the first
+// 16 colors look differently in every terminal theme.
+func RGBTo256(r, g, b int) int {
+	r, g, b = clamp8(r), clamp8(g), clamp8(b)
+	cube := func(v int) int {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	cr, cg, cb := cube(r), cube(g), cube(b)
+	cubeIndex := 16 + 36*cr + 6*cg + cb
+	cubeDist := distance(r, g, b, cubeLevels[cr], cubeLevels[cg], cubeLevels[cb])
+
+	avg := (r + g + b) / 3
+	grayStep := 0
+	if avg > 238 {
+		grayStep = 23
+	} else if avg > 8 {
+		grayStep = (avg - 3) / 10
+	}
+	gray := 8 + 10*grayStep
+	grayDist := distance(r, g, b, gray, gray, gray)
+
+	if grayDist < cubeDist {
+		return 232 + grayStep
+	}
+	return cubeIndex
+}
+
+// RGBFrom256 returns RGB components of the 256-color palette entry n. Values of
+// n are limited to 0–255.
+func RGBFrom256(n int) (r, g, b int) {
+	c := palette256[clamp8(n)]
+	return c[0], c[1], c[2]
+}
+
+// RGBTo16 returns the index (0–15) of the named color nearest to the RGB color,
+// assuming the named colors look the way xterm shows them by default.
+func RGBTo16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range palette256[:16] {
+		d := distance(r, g, b, c[0], c[1], c[2])
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// distance is a cheap perceptual ("redmean") approximation of the squared
+// distance between two colors.
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	rm := (r1 + r2) / 2
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return ((512+rm)*dr*dr)>>8 + 4*dg*dg + ((767-rm)*db*db)>>8
+}
+
+// clamp8 limits v to a color component range of 0–255.
+func clamp8(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return v
+}
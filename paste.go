@@ -0,0 +1,81 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultMaxPasteSize limits the text of a PasteEvent unless SetMaxPasteSize
+// says otherwise.
+const DefaultMaxPasteSize = 1 << 20
+
+// pasteEnd is the mark terminals send after pasted text in bracketed paste
+// mode.
+var pasteEnd = []byte(CSI + "201~")
+
+// PasteEvent is text pasted into the terminal while SetBracketedPaste is on,
+// delivered at once instead of as separate keys, so that pasted newlines are
+// not taken for Enter. Line endings are converted to "\n".
+type PasteEvent struct {
+	Text string
+	// Truncated reports whether the text has been cut to the maximum paste
+	// size, see SetMaxPasteSize.
+	Truncated bool
+}
+
+func (PasteEvent) isEvent() {}
+
+// pasteStart is decoded from the mark terminals send before pasted text. It
+// is never delivered.
+type pasteStart struct{}
+
+func (pasteStart) isEvent() {}
+
+// SetMaxPasteSize limits the size of PasteEvent text in bytes, protecting from
+// huge amounts of text pasted by mistake. The rest of the text is discarded.
+// DefaultMaxPasteSize is used when n is 0.
+func (t *Terminal) SetMaxPasteSize(n int) {
+	atomic.StoreInt64(&t.maxPaste, int64(n))
+}
+
+func (t *Terminal) maxPasteSize() int {
+	if n := int(atomic.LoadInt64(&t.maxPaste)); n > 0 {
+		return n
+	}
+	return DefaultMaxPasteSize
+}
+
+// pasteText collects pasted text from p and returns the rest of p, delivering
+// PasteEvent when the end mark is found. done is false when all of p is
+// taken, except for what may be the beginning of the end mark.
+func (r *inputReader) pasteText(p []byte) (rest []byte, done bool) {
+	text := p
+	end := bytes.Index(p, pasteEnd)
+	if end >= 0 {
+		text, rest = p[:end], p[end+len(pasteEnd):]
+	} else {
+		// Keep what may be the beginning of the end mark
+		keep := 0
+		for i := len(pasteEnd) - 1; i > 0; i-- {
+			if bytes.HasSuffix(p, pasteEnd[:i]) {
+				keep = i
+				break
+			}
+		}
+		text, rest = p[:len(p)-keep], p[len(p)-keep:]
+	}
+	if room := r.t.maxPasteSize() - len(r.paste); len(text) > room {
+		text = text[:room]
+		r.truncated = true
+	}
+	r.paste = append(r.paste, text...)
+	if end < 0 {
+		return rest, false
+	}
+	s := strings.ReplaceAll(string(r.paste), "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	r.send(PasteEvent{Text: s, Truncated: r.truncated})
+	r.paste, r.pasting, r.truncated = nil, false, false
+	return rest, true
+}
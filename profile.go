@@ -0,0 +1,162 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ColorProfile describes how many colors a terminal is able to display.
+// Profiles are ordered, so that a profile supports everything that a lower one
+// does.
+type ColorProfile int
+
+const (
+	NoColor   ColorProfile = iota // NoColor strips all color sequences.
+	ANSI16                        // ANSI16 supports 8 basic and 8 bright colors: 30–37, 90–97 and their backgrounds.
+	ANSI256                       // ANSI256 supports xterm 256-color palette: 38;5;<n> and 48;5;<n>.
+	TrueColor                     // TrueColor supports 24-bit RGB colors: 38;2;<r>;<g>;<b> and 48;2;<r>;<g>;<b>.
+)
+
+func (p ColorProfile) String() string {
+	switch p {
+	case NoColor:
+		return "NoColor"
+	case ANSI16:
+		return "ANSI16"
+	case ANSI256:
+		return "ANSI256"
+	case TrueColor:
+		return "TrueColor"
+	}
+	return "ColorProfile(" + strconv.Itoa(int(p)) + ")"
+}
+
+// Convert rewrites color sequences found in s to the nearest colors supported
+// by the profile. Sequences other than SGR ("CSI ... m") are left untouched.
+func (p ColorProfile) Convert(s string) string {
+	if p >= TrueColor {
+		return s
+	}
+	var b strings.Builder
+	w := &filterWriter{w: &b, profile: p, style: true, controls: true}
+	_, _ = w.Write([]byte(s))
+	_ = w.Flush()
+	return b.String()
+}
+
+// DetectColorProfile guesses the color profile of the terminal from the
+// environment variables:
+//
+// COLORTERM=truecolor or COLORTERM=24bit means TrueColor.
+//
+// TERM containing "256color" means ANSI256, "direct" or "truecolor" means
+// TrueColor and "dumb" means NoColor.
+//
+// FORCE_TERMINAL_SEQUENCES=1 with no other hints means TrueColor, so that the
+// parent process, such as exexec, is the one that downgrades the colors.
+//
+// DetectColorProfile doesn't check whether the output is a terminal and
+// doesn't take into account user preferences such as NO_COLOR. See
+// DetectColorMode for that.
+func DetectColorProfile() ColorProfile {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	if os.Getenv("WT_SESSION") != "" { // Windows Terminal
+		return TrueColor
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper":
+		return TrueColor
+	case "Apple_Terminal":
+		return ANSI256
+	}
+	term := os.Getenv("TERM")
+	if term == "" && (runtime.GOOS == "windows" || os.Getenv("FORCE_TERMINAL_SEQUENCES") == "1") {
+		return TrueColor
+	}
+	return profileFromTerm(term)
+}
+
+// profileFromTerm guesses the color profile from the value of TERM, which is
+// ANSI16 unless it tells otherwise.
+func profileFromTerm(term string) ColorProfile {
+	t := strings.ToLower(term)
+	switch {
+	case t == "dumb":
+		return NoColor
+	case strings.Contains(t, "direct"), strings.Contains(t, "truecolor"), strings.Contains(t, "24bit"):
+		return TrueColor
+	case strings.Contains(t, "256color"):
+		return ANSI256
+	}
+	return ANSI16
+}
+
+// ColorMode tells whether colors should be output.
+type ColorMode int
+
+const (
+	// ColorAuto outputs colors only if the output is a terminal and the user
+	// hasn't asked otherwise using the environment variables.
+	ColorAuto ColorMode = iota
+	// ColorAlways outputs colors even if the output is not a terminal.
+	ColorAlways
+	// ColorNever strips colors while keeping the rest of sequences, such as
+	// cursor movement, if the output is a terminal.
+	ColorNever
+)
+
+func (m ColorMode) String() string {
+	switch m {
+	case ColorAuto:
+		return "auto"
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	}
+	return "ColorMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseColorMode converts "auto", "always" or "never", typically the values of a
+// --color command line flag, to ColorMode.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(s) {
+	case "auto", "":
+		return ColorAuto, nil
+	case "always", "force":
+		return ColorAlways, nil
+	case "never", "none":
+		return ColorNever, nil
+	}
+	return ColorAuto, fmt.Errorf("invalid color mode %q, expected auto, always or never", s)
+}
+
+// DetectColorMode returns the color mode requested by the user using the
+// widely adopted environment variables:
+//
+// NO_COLOR set to any non-empty value means ColorNever (https://no-color.org).
+//
+// CLICOLOR_FORCE set to anything but "0" means ColorAlways.
+//
+// CLICOLOR=0 means ColorNever.
+//
+// NO_COLOR takes precedence over CLICOLOR_FORCE, and CLICOLOR_FORCE takes
+// precedence over CLICOLOR. ColorAuto is returned if none of them are set.
+func DetectColorMode() ColorMode {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNever
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return ColorAlways
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return ColorNever
+	}
+	return ColorAuto
+}
@@ -0,0 +1,237 @@
+// Package prompts asks the user questions in the terminal: yes or no with
+// Confirm, one or more of options with Select and MultiSelect, and a line of
+// text with Input.
+//
+// Prompts are rendered inline below the cursor and are replaced with a single
+// line summarizing the answer once it's given. They return
+// terminal.ErrInterrupted when the user presses Ctrl+C and
+// terminal.NotTerminalError when either input or output is not a terminal, so
+// that a default can be used instead:
+//
+//	ok, err := prompts.Confirm(ctx, t, "Overwrite the file?", false)
+//	if errors.Is(err, terminal.ErrNotTerminal) {
+//		ok = force
+//	} else if err != nil {
+//		return err
+//	}
+package prompts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zzwx/terminal"
+)
+
+var (
+	questionStyle = terminal.Style{Fg: terminal.Green, Bold: true}
+	answerStyle   = terminal.Style{Fg: terminal.Cyan}
+	hintStyle     = terminal.Style{Dim: true}
+	errorStyle    = terminal.Style{Fg: terminal.Red}
+)
+
+// Confirm asks a yes or no question, answered with y or n. Enter answers def.
+func Confirm(ctx context.Context, t *terminal.Terminal, question string, def bool) (bool, error) {
+	restore, err := enterRaw(t)
+	if err != nil {
+		return false, err
+	}
+	defer restore()
+	hint := " [y/N] "
+	if def {
+		hint = " [Y/n] "
+	}
+	v := view{t: t}
+	v.draw(header(question)+hintStyle.Render(hint), -1, nil)
+	for {
+		k, err := t.ReadKey(ctx)
+		if err != nil {
+			v.clear()
+			return false, err
+		}
+		answer := def
+		switch {
+		case k.Action == terminal.KeyRelease:
+			continue
+		case k.IsCtrl('c'):
+			v.clear()
+			return false, terminal.ErrInterrupted
+		case k.Key == terminal.KeyEnter:
+		case k.Mod == 0 && (k.Rune == 'y' || k.Rune == 'Y'):
+			answer = true
+		case k.Mod == 0 && (k.Rune == 'n' || k.Rune == 'N'):
+			answer = false
+		default:
+			continue
+		}
+		text := "no"
+		if answer {
+			text = "yes"
+		}
+		v.done(header(question) + " " + answerStyle.Render(text))
+		return answer, nil
+	}
+}
+
+// Input asks for a line of text, which is def unless edited. If validate is
+// not nil, the line is accepted only when it returns nil, otherwise the error
+// is shown below the line until it's edited.
+func Input(ctx context.Context, t *terminal.Terminal, label, def string, validate func(string) error) (string, error) {
+	restore, err := enterRaw(t)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	v := view{t: t}
+	line := []rune(def)
+	pos := len(line)
+	var invalid error
+	for {
+		v.drawInput(header(label)+" ", line, pos, invalid)
+		k, err := t.ReadKey(ctx)
+		if err != nil {
+			v.clear()
+			return "", err
+		}
+		if k.Action == terminal.KeyRelease {
+			continue
+		}
+		edited := true
+		switch {
+		case k.IsCtrl('c'):
+			v.clear()
+			return "", terminal.ErrInterrupted
+		case k.Key == terminal.KeyEnter:
+			s := string(line)
+			if validate != nil {
+				if invalid = validate(s); invalid != nil {
+					continue
+				}
+			}
+			v.done(header(label) + " " + answerStyle.Render(s))
+			return s, nil
+		case k.Key == terminal.KeyLeft || k.IsCtrl('b'):
+			if pos > 0 {
+				pos--
+			}
+			edited = false
+		case k.Key == terminal.KeyRight || k.IsCtrl('f'):
+			if pos < len(line) {
+				pos++
+			}
+			edited = false
+		case k.Key == terminal.KeyHome || k.IsCtrl('a'):
+			pos, edited = 0, false
+		case k.Key == terminal.KeyEnd || k.IsCtrl('e'):
+			pos, edited = len(line), false
+		case k.Key == terminal.KeyBackspace || k.IsCtrl('h'):
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case k.Key == terminal.KeyDelete || k.IsCtrl('d'):
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case k.IsCtrl('u'):
+			line, pos = line[pos:], 0
+		case k.IsCtrl('k'):
+			line = line[:pos]
+		case k.Key == terminal.KeyRune && k.Mod&(terminal.ModCtrl|terminal.ModAlt) == 0:
+			line = append(line[:pos], append([]rune{k.Rune}, line[pos:]...)...)
+			pos++
+		default:
+			edited = false
+		}
+		if edited {
+			invalid = nil
+		}
+	}
+}
+
+// header renders the question or label of a prompt.
+func header(label string) string {
+	return questionStyle.Render("?") + " " + label
+}
+
+// enterRaw puts the terminal into raw mode for the time of a prompt, failing
+// with NotTerminalError if either input or output is not a terminal.
+func enterRaw(t *terminal.Terminal) (restore func() error, err error) {
+	if !t.IsTerminal() {
+		return nil, &terminal.NotTerminalError{}
+	}
+	return t.EnterRaw()
+}
+
+// view draws a prompt in place: a header line with the cursor in it and lines
+// under it.
+type view struct {
+	t *terminal.Terminal
+}
+
+// draw replaces the prompt with header and lines. The cursor is left in the
+// header after x columns, or at its end if x is negative. Lines must fit the
+// width of the terminal, as the rows they take are counted.
+func (v *view) draw(header string, x int, lines []string) {
+	v.t.Print("\r")
+	v.t.EraseRestOfScreen()
+	var b strings.Builder
+	b.WriteString(header)
+	for _, line := range lines {
+		b.WriteString("\r\n" + line)
+	}
+	v.t.Print(b.String())
+	if x < 0 {
+		x = terminal.StringWidth(header)
+	}
+	v.t.MoveByY(-len(lines)).MoveToX(x)
+}
+
+// drawInput draws the prompt of Input, scrolling line horizontally to keep pos
+// visible.
+func (v *view) drawInput(prompt string, line []rune, pos int, invalid error) {
+	width, _ := v.t.GetSize()
+	room := width - 1 - terminal.StringWidth(prompt)
+	start := 0
+	for start < pos && terminal.StringWidth(string(line[start:pos])) > room {
+		start++
+	}
+	shown := truncate(string(line[start:]), room)
+	var lines []string
+	if invalid != nil {
+		lines = []string{errorStyle.Render(truncate(invalid.Error(), width-1))}
+	}
+	v.draw(prompt+shown, terminal.StringWidth(prompt+string(line[start:pos])), lines)
+}
+
+// done replaces the prompt with summary and moves to the next line.
+func (v *view) done(summary string) {
+	v.t.Print("\r")
+	v.t.EraseRestOfScreen().Print(summary + "\r\n")
+}
+
+// clear erases the prompt.
+func (v *view) clear() {
+	v.t.Print("\r")
+	v.t.EraseRestOfScreen()
+}
+
+// truncate cuts s to at most width columns, ending with "…" when cut. s must not
+// contain escape sequences.
+func truncate(s string, width int) string {
+	if terminal.StringWidth(s) <= width {
+		return s
+	}
+	w := 0
+	for i, r := range s {
+		rw := terminal.RuneWidth(r)
+		if w+rw > width-1 {
+			if width < 1 {
+				return ""
+			}
+			return s[:i] + "…"
+		}
+		w += rw
+	}
+	return s
+}
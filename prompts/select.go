@@ -0,0 +1,222 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/zzwx/terminal"
+)
+
+// ErrNoOptions is returned by Select and MultiSelect when there are no options
+// to choose from.
+var ErrNoOptions = errors.New("no options to choose from")
+
+// PageSize is the maximum number of options Select and MultiSelect show at
+// once. The rest are scrolled to.
+var PageSize = 10
+
+// Select asks to choose one of options and returns its index. Up and Down move
+// between options, and typing filters them to those containing the typed text.
+func Select(ctx context.Context, t *terminal.Terminal, label string, options []string) (int, error) {
+	l := list{options: options}
+	if err := l.run(ctx, t, label, "type to filter"); err != nil {
+		return -1, err
+	}
+	return l.shown[l.cursor], nil
+}
+
+// MultiSelect asks to choose any number of options and returns their indexes in
+// order. Space toggles the option under the cursor, and typing other
+// characters filters options the same way Select does.
+func MultiSelect(ctx context.Context, t *terminal.Terminal, label string, options []string) ([]int, error) {
+	l := list{options: options, checked: make(map[int]bool)}
+	if err := l.run(ctx, t, label, "space to toggle, type to filter"); err != nil {
+		return nil, err
+	}
+	chosen := make([]int, 0, len(l.checked))
+	for i := range l.checked {
+		chosen = append(chosen, i)
+	}
+	sort.Ints(chosen)
+	return chosen, nil
+}
+
+// list is the state of Select and MultiSelect.
+type list struct {
+	options []string
+	checked map[int]bool // Checked options of MultiSelect, nil for Select
+	filter  []rune
+	shown   []int // Indexes of options matching filter
+	cursor  int   // Index in shown of the option under the cursor
+	top     int   // Index in shown of the first visible option
+}
+
+// run lets the user choose until Enter is pressed.
+func (l *list) run(ctx context.Context, t *terminal.Terminal, label, hint string) error {
+	if len(l.options) == 0 {
+		return ErrNoOptions
+	}
+	restore, err := enterRaw(t)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	v := view{t: t}
+	l.applyFilter()
+	for {
+		l.draw(&v, label, hint)
+		k, err := t.ReadKey(ctx)
+		if err != nil {
+			v.clear()
+			return err
+		}
+		switch {
+		case k.Action == terminal.KeyRelease:
+		case k.IsCtrl('c'):
+			v.clear()
+			return terminal.ErrInterrupted
+		case k.Key == terminal.KeyEnter:
+			if l.checked == nil && len(l.shown) == 0 {
+				continue
+			}
+			v.done(header(label) + " " + answerStyle.Render(l.summary()))
+			return nil
+		case k.Key == terminal.KeyUp || k.IsCtrl('p'):
+			l.move(-1, true)
+		case k.Key == terminal.KeyDown || k.IsCtrl('n'):
+			l.move(1, true)
+		case k.Key == terminal.KeyPageUp:
+			l.move(-l.pageSize(t), false)
+		case k.Key == terminal.KeyPageDown:
+			l.move(l.pageSize(t), false)
+		case k.Key == terminal.KeyBackspace || k.IsCtrl('h'):
+			if len(l.filter) > 0 {
+				l.filter = l.filter[:len(l.filter)-1]
+				l.applyFilter()
+			}
+		case k.IsCtrl('u') || k.Key == terminal.KeyEscape:
+			l.filter = nil
+			l.applyFilter()
+		case l.checked != nil && k.Key == terminal.KeyRune && k.Rune == ' ' && k.Mod == 0:
+			if len(l.shown) > 0 {
+				i := l.shown[l.cursor]
+				if l.checked[i] {
+					delete(l.checked, i)
+				} else {
+					l.checked[i] = true
+				}
+			}
+		case k.Key == terminal.KeyRune && k.Mod&(terminal.ModCtrl|terminal.ModAlt) == 0:
+			l.filter = append(l.filter, k.Rune)
+			l.applyFilter()
+		}
+	}
+}
+
+// applyFilter finds options matching the filter, keeping the cursor on the
+// same option if it's still shown.
+func (l *list) applyFilter() {
+	current := -1
+	if l.cursor < len(l.shown) {
+		current = l.shown[l.cursor]
+	}
+	filter := strings.ToLower(string(l.filter))
+	l.shown = l.shown[:0]
+	l.cursor, l.top = 0, 0
+	for i, o := range l.options {
+		if strings.Contains(strings.ToLower(o), filter) {
+			if i == current {
+				l.cursor = len(l.shown)
+			}
+			l.shown = append(l.shown, i)
+		}
+	}
+}
+
+// move moves the cursor by n options, wrapping around the ends if wrap is
+// true.
+func (l *list) move(n int, wrap bool) {
+	if len(l.shown) == 0 {
+		return
+	}
+	l.cursor += n
+	switch {
+	case wrap:
+		l.cursor = (l.cursor%len(l.shown) + len(l.shown)) % len(l.shown)
+	case l.cursor < 0:
+		l.cursor = 0
+	case l.cursor >= len(l.shown):
+		l.cursor = len(l.shown) - 1
+	}
+}
+
+// pageSize returns the number of options shown at once, which are limited by
+// PageSize and the height of the terminal.
+func (l *list) pageSize(t *terminal.Terminal) int {
+	_, height := t.GetSize()
+	n := PageSize
+	if n > height-2 {
+		n = height - 2
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// draw renders the prompt with the visible options under it.
+func (l *list) draw(v *view, label, hint string) {
+	width, _ := v.t.GetSize()
+	prompt := header(label) + " " + string(l.filter)
+	x := terminal.StringWidth(prompt)
+	if len(l.filter) == 0 {
+		prompt += hintStyle.Render(truncate("("+hint+")", width-1-x))
+	}
+	page := l.pageSize(v.t)
+	if l.cursor < l.top {
+		l.top = l.cursor
+	}
+	if l.cursor >= l.top+page {
+		l.top = l.cursor - page + 1
+	}
+	var lines []string
+	if len(l.shown) == 0 {
+		lines = append(lines, hintStyle.Render(truncate("no matches", width-1)))
+	}
+	for i := l.top; i < len(l.shown) && i < l.top+page; i++ {
+		option := l.shown[i]
+		marker := "  "
+		if i == l.cursor {
+			marker = "> "
+		}
+		if l.checked != nil {
+			if l.checked[option] {
+				marker += "[x] "
+			} else {
+				marker += "[ ] "
+			}
+		}
+		line := marker + truncate(l.options[option], width-1-len(marker))
+		if i == l.cursor {
+			line = answerStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	v.draw(prompt, x, lines)
+}
+
+// summary returns the chosen options.
+func (l *list) summary() string {
+	if l.checked == nil {
+		return l.options[l.shown[l.cursor]]
+	}
+	var chosen []string
+	for i, o := range l.options {
+		if l.checked[i] {
+			chosen = append(chosen, o)
+		}
+	}
+	return strings.Join(chosen, ", ")
+}
@@ -0,0 +1,133 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotTerminal matches NotTerminalError returned when an operation
+	// requires a terminal, see errors.Is.
+	ErrNotTerminal = errors.New("not a terminal")
+	// ErrNoReply is returned by queries when the terminal doesn't answer in
+	// time, which usually means it doesn't support the query.
+	ErrNoReply = errors.New("no reply from terminal")
+)
+
+// DefaultQueryTimeout is a reasonable time to wait for a terminal to answer a
+// query, including terminals connected over the network.
+const DefaultQueryTimeout = 500 * time.Millisecond
+
+// requestPrimaryAttributes is "Primary Device Attributes" (DA1) request that
+// every terminal answers with "CSI ? ... c".
+const requestPrimaryAttributes = CSI + "c"
+
+// SetInput sets the file used to read replies to queries and the user input,
+// os.Stdin by default.
+func (t *Terminal) SetInput(f *os.File) {
+	t.in = f
+}
+
+// rawInput puts the input into raw mode unless it already is, see EnterRaw,
+// and returns a function restoring it. NotTerminalError is returned if either
+// input or output is not a terminal.
+func (t *Terminal) rawInput() (restore func() error, err error) {
+	t.init()
+	if !t.IsTerminal() {
+		return nil, &NotTerminalError{}
+	}
+	return t.EnterRaw()
+}
+
+// exchange sends request to the terminal in raw mode and collects escape
+// sequences arriving in reply until last reports true for one of them or
+// timeout expires. Anything but escape sequences, such as keys typed in the
+// meantime, is discarded.
+func (t *Terminal) exchange(request string, timeout time.Duration, last func(seq []byte) bool) ([][]byte, error) {
+	restore, err := t.rawInput()
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+	r := t.activeInput()
+	if r == nil && t.streams != nil {
+		r = t.input() // Streams can't be waited for
+	}
+	if r != nil {
+		// Replies arrive through the input reader
+		atomic.AddInt32(&r.waiting, 1)
+		defer atomic.AddInt32(&r.waiting, -1)
+		for len(r.replies) > 0 {
+			<-r.replies // Late replies to previous queries
+		}
+	}
+	if _, err := t.Print(request); err != nil {
+		return nil, err
+	}
+	if r != nil {
+		return r.collect(timeout, last)
+	}
+	var seqs [][]byte
+	var pending []byte
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return seqs, ErrNoReply
+		}
+		ok, err := waitReadable(t.in, remaining)
+		if err != nil {
+			return seqs, err
+		}
+		if !ok {
+			return seqs, ErrNoReply
+		}
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return seqs, err
+		}
+		pending = append(pending, buf[:n]...)
+		for len(pending) > 0 {
+			if pending[0] != ESC[0] {
+				pending = pending[1:]
+				continue
+			}
+			l, complete := sequenceLen(pending)
+			if !complete {
+				break
+			}
+			seq := append([]byte(nil), pending[:l]...)
+			pending = pending[l:]
+			seqs = append(seqs, seq)
+			if last(seq) {
+				return seqs, nil
+			}
+		}
+	}
+}
+
+// queryWithFallback sends request followed by the primary device attributes
+// request and returns the reply for which match reports true. Since every
+// terminal answers the latter, it arriving first means that the request is not
+// supported, which is reported as ErrNoReply without waiting for the timeout.
+func (t *Terminal) queryWithFallback(request string, timeout time.Duration, match func(seq []byte) bool) ([]byte, error) {
+	seqs, err := t.exchange(request+requestPrimaryAttributes, timeout, isPrimaryAttributes)
+	for _, seq := range seqs {
+		if match(seq) {
+			return seq, nil
+		}
+	}
+	if err == nil {
+		err = ErrNoReply
+	}
+	return nil, err
+}
+
+// isPrimaryAttributes reports whether seq is a reply to the primary device
+// attributes request: "CSI ? ... c".
+func isPrimaryAttributes(seq []byte) bool {
+	return len(seq) > 3 && seq[1] == '[' && seq[2] == '?' && seq[len(seq)-1] == 'c'
+}
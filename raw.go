@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// NotTerminalError is returned when an operation requires a terminal, but the
+// input or output is not one, such as when it's redirected to a file. It
+// matches ErrNotTerminal with errors.Is.
+type NotTerminalError struct {
+	// Name is the name of the file, such as "/dev/stdin", or empty if it's
+	// unknown.
+	Name string
+}
+
+func (e *NotTerminalError) Error() string {
+	if e.Name == "" {
+		return "not a terminal"
+	}
+	return e.Name + " is not a terminal"
+}
+
+// Is reports whether target is ErrNotTerminal.
+func (e *NotTerminalError) Is(target error) bool {
+	return target == ErrNotTerminal
+}
+
+// EnterRaw puts the terminal input into raw mode, where every key is available
+// as soon as it's pressed without echo and Ctrl+C doesn't send SIGINT, and
+// returns a function restoring the previous mode. If the terminal is already
+// in raw mode, restore does nothing.
+func (t *Terminal) EnterRaw() (restore func() error, err error) {
+	t.init()
+	fd, err := t.inputFd()
+	if err != nil {
+		return nil, err
+	}
+	if fd < 0 {
+		return func() error { return nil }, nil
+	}
+	t.modeMu.Lock()
+	defer t.modeMu.Unlock()
+	if t.raw != nil {
+		return func() error { return nil }, nil
+	}
+	state, err := makeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	t.raw, t.rawFd = state, fd
+	t.record("SetRaw", []interface{}{true})
+	return t.restoreRaw, nil
+}
+
+// ExitRaw restores the mode the terminal has been in before EnterRaw or
+// SetRaw, also popping keyboard flags pushed with PushKeyboardFlags. It does
+// nothing if the terminal is not in raw mode.
+func (t *Terminal) ExitRaw() error {
+	t.modeMu.Lock()
+	defer t.modeMu.Unlock()
+	t.popKeyboardFlags(t.keyboardPushes)
+	return t.exitRaw()
+}
+
+// restoreRaw is the restore function returned by EnterRaw, which leaves
+// keyboard flags alone.
+func (t *Terminal) restoreRaw() error {
+	t.modeMu.Lock()
+	defer t.modeMu.Unlock()
+	return t.exitRaw()
+}
+
+// exitRaw restores the mode the terminal has been in before raw mode. t.modeMu
+// must be held.
+func (t *Terminal) exitRaw() error {
+	if t.raw == nil {
+		return nil
+	}
+	if err := term.Restore(t.rawFd, t.raw); err != nil {
+		return err
+	}
+	t.raw = nil
+	t.record("SetRaw", []interface{}{false})
+	return nil
+}
+
+// EnterCbreak puts the terminal input into cbreak mode, where every key is
+// available as soon as it's pressed without echo, like in raw mode, but Ctrl+C
+// still sends SIGINT and output is processed as usual, so "\n" starts a new
+// line. It returns a function restoring the previous mode, which does nothing
+// when called again.
+func (t *Terminal) EnterCbreak() (restore func() error, err error) {
+	t.init()
+	fd, err := t.inputFd()
+	if err != nil {
+		return nil, err
+	}
+	if fd < 0 {
+		return func() error { return nil }, nil
+	}
+	restoreMode, err := makeCbreak(fd)
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	var restoreErr error
+	restore = func() error {
+		once.Do(func() {
+			restoreErr = restoreMode()
+			t.record("ExitCbreak", nil)
+		})
+		return restoreErr
+	}
+	t.record("EnterCbreak", []interface{}{restore})
+	return restore, nil
+}
+
+// inputFd returns the file descriptor of the input if it's a terminal, or -1
+// for a terminal connected through streams, which modes can't be changed.
+func (t *Terminal) inputFd() (int, error) {
+	if t.streams != nil {
+		if !t.isTerm {
+			return 0, &NotTerminalError{}
+		}
+		return -1, nil
+	}
+	fd := int(t.in.Fd())
+	if !IsTerminal(fd) {
+		return 0, &NotTerminalError{Name: t.in.Name()}
+	}
+	return fd, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package terminal
+
+import "golang.org/x/term"
+
+// makeRaw puts terminal fd into raw mode.
+func makeRaw(fd int) (*term.State, error) {
+	return term.MakeRaw(fd)
+}
@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import (
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+// makeRaw puts console fd into raw mode, also enabling virtual terminal input,
+// which term.MakeRaw leaves off, so that keys arrive as escape sequences.
+func makeRaw(fd int) (*term.State, error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	h := windows.Handle(fd)
+	var mode uint32
+	err = windows.GetConsoleMode(h, &mode)
+	if err == nil {
+		err = windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_INPUT)
+	}
+	if err != nil {
+		term.Restore(fd, state)
+		return nil, err
+	}
+	return state, nil
+}
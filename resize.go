@@ -0,0 +1,220 @@
+package terminal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// resizeDelay is how long the size has to stay the same after a change before
+// it's reported, as resizing a window with the mouse changes it many times.
+const resizeDelay = 50 * time.Millisecond
+
+// ResizeEvent reports that the terminal window has been resized. It's
+// delivered by ReadEvent and Events along with the input.
+type ResizeEvent struct {
+	Size
+}
+
+func (ResizeEvent) isEvent() {}
+
+// OnResize calls f with the new size whenever the terminal window is resized.
+// It's called from a separate goroutine, one change after another. The
+// returned function unregisters f. Resizes are watched only while there are
+// handlers, ResizeEvents channels or the input is read.
+func (t *Terminal) OnResize(f func(w, h int)) (unregister func()) {
+	w := t.resizeWatcher()
+	h := &resizeHandler{f}
+	w.mu.Lock()
+	w.handlers = append(w.handlers, h)
+	w.acquire()
+	w.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			handlers := make([]*resizeHandler, 0, len(w.handlers))
+			for _, other := range w.handlers {
+				if other != h {
+					handlers = append(handlers, other)
+				}
+			}
+			w.handlers = handlers
+			w.release()
+		})
+	}
+}
+
+// ResizeEvents returns a channel receiving the new size whenever the terminal
+// window is resized, which is closed when ctx is done. Only the last size is
+// kept when it isn't received in time.
+func (t *Terminal) ResizeEvents(ctx context.Context) <-chan Size {
+	w := t.resizeWatcher()
+	c := make(chan Size, 1)
+	w.mu.Lock()
+	w.subscribers[c] = struct{}{}
+	w.acquire()
+	w.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		delete(w.subscribers, c)
+		w.release()
+		w.mu.Unlock()
+		close(c)
+	}()
+	return c
+}
+
+// resizeWatcher reports changes of the window size, which it learns about from
+// SIGWINCH on Unix, by polling elsewhere and from SetSize for streams.
+type resizeWatcher struct {
+	t           *Terminal
+	changes     chan struct{} // Notified when the size may have changed
+	mu          sync.Mutex
+	handlers    []*resizeHandler
+	subscribers map[chan Size]struct{}
+	// users is the number of handlers, subscribers and input readers, which
+	// the watcher runs for until stop is called once there are none.
+	users int
+	stop  func()
+}
+
+// resizeHandler is a function registered with OnResize.
+type resizeHandler struct {
+	f func(w, h int)
+}
+
+// resizeWatcher returns the watcher of the window size, which runs while it
+// has users, see acquire.
+func (t *Terminal) resizeWatcher() *resizeWatcher {
+	t.init()
+	t.resizeOnce.Do(func() {
+		t.resize = &resizeWatcher{
+			t:           t,
+			changes:     make(chan struct{}, 1),
+			subscribers: make(map[chan Size]struct{}),
+		}
+	})
+	return t.resize
+}
+
+// acquire adds a user of the watcher, starting it for the first one. w.mu
+// must be held.
+func (w *resizeWatcher) acquire() {
+	w.users++
+	if w.users > 1 {
+		return
+	}
+	stopNotify := func() {}
+	if w.t.streams == nil { // Otherwise SetSize notifies changes
+		stopNotify = notifyResize(w.t, w.changes)
+	}
+	quit := make(chan struct{})
+	last, _ := w.size()
+	go w.run(last, quit)
+	w.stop = func() {
+		stopNotify()
+		close(quit)
+	}
+}
+
+// release removes a user of the watcher, stopping it after the last one. w.mu
+// must be held.
+func (w *resizeWatcher) release() {
+	w.users--
+	if w.users == 0 {
+		w.stop()
+		w.stop = nil
+	}
+}
+
+func (w *resizeWatcher) run(last Size, quit <-chan struct{}) {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-quit:
+			return // Leaving changes to the next run
+		default:
+		}
+		select {
+		case <-w.changes:
+		case <-quit:
+			return
+		}
+		// Wait until the size stops changing
+		timer.Reset(resizeDelay)
+	settle:
+		for {
+			select {
+			case <-quit:
+				return
+			case <-w.changes:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(resizeDelay)
+			case <-timer.C:
+				break settle
+			}
+		}
+		size, ok := w.size()
+		if !ok || size == last {
+			continue
+		}
+		last = size
+		w.deliver(size)
+	}
+}
+
+// size returns the current size of the window.
+func (w *resizeWatcher) size() (Size, bool) {
+	size, err := w.t.windowSize()
+	return size, err == nil
+}
+
+// deliver reports size to handlers, subscribers and the input reader.
+func (w *resizeWatcher) deliver(size Size) {
+	if r := w.t.activeInput(); r != nil {
+		sendLatest(r.resize, size)
+	}
+	w.mu.Lock()
+	handlers := w.handlers
+	for c := range w.subscribers {
+		sendLatest(c, size)
+	}
+	w.mu.Unlock()
+	for _, h := range handlers {
+		h.f(size.Width, size.Height)
+	}
+}
+
+// sendLatest sends size to c, which has a buffer of one, replacing a size
+// that hasn't been received yet.
+func sendLatest(c chan Size, size Size) {
+	for {
+		select {
+		case c <- size:
+			return
+		default:
+		}
+		select {
+		case <-c:
+		default:
+		}
+	}
+}
+
+// notifyChange sends to c without waiting, as one pending change is enough.
+func notifyChange(c chan<- struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package terminal
+
+// notifyResize does nothing on this platform.
+func notifyResize(t *Terminal, c chan<- struct{}) (stop func()) {
+	return func() {}
+}
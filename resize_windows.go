@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import "time"
+
+// resizePollInterval is how often the window size is checked on Windows,
+// which has no signal for it.
+const resizePollInterval = 250 * time.Millisecond
+
+// notifyResize notifies c when the window of t may have been resized until
+// stop is called.
+func notifyResize(t *Terminal, c chan<- struct{}) (stop func()) {
+	ticker := time.NewTicker(resizePollInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				notifyChange(c)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
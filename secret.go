@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SecretOptions configure Terminal.ReadSecret.
+type SecretOptions struct {
+	// Mask is output for every typed character, such as '*'. Nothing is output
+	// if it's 0.
+	Mask rune
+	// MaxLength limits the number of characters, or is unlimited if 0.
+	MaxLength int
+	// Context cancels reading, or is context.Background() if nil.
+	Context context.Context
+}
+
+// ReadSecret outputs prompt and reads a password or another secret without
+// showing it, putting the terminal into raw mode for the time of reading unless
+// it already is. Backspace deletes the last character and Ctrl+U all of them.
+// Ctrl+C makes it return ErrInterrupted and Ctrl+D with nothing typed io.EOF.
+//
+// The secret is returned as a byte slice, so that it can be zeroed once no
+// longer needed. If the input is not a terminal, a line is read as is, which
+// allows secrets to be piped. If only the output is not a terminal, the input
+// is still read in raw mode, so that the secret isn't echoed, but the mask is
+// not output.
+func (t *Terminal) ReadSecret(prompt string, opts SecretOptions) ([]byte, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Whether the secret is echoed depends on the input only
+	restore, err := t.EnterRaw()
+	t.Print(prompt)
+	if errors.Is(err, ErrNotTerminal) {
+		return t.readPlainSecret(ctx, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+	var s secret
+	mask := ""
+	if opts.Mask != 0 && t.IsTerminal() {
+		mask = string(opts.Mask)
+	}
+	erase := strings.Repeat("\b", StringWidth(mask)) + strings.Repeat(" ", StringWidth(mask)) + strings.Repeat("\b", StringWidth(mask))
+	add := func(r rune) {
+		if opts.MaxLength > 0 && s.runes >= opts.MaxLength {
+			t.Print("\a")
+			return
+		}
+		s.add(r)
+		t.Print(mask)
+	}
+	for {
+		ev, err := t.ReadEvent(ctx)
+		if err != nil {
+			s.zero()
+			t.Print("\r\n")
+			return nil, err
+		}
+		switch ev := ev.(type) {
+		case PasteEvent:
+			for _, r := range ev.Text {
+				if r >= ' ' {
+					add(r)
+				}
+			}
+		case KeyEvent:
+			switch {
+			case ev.Action == KeyRelease:
+			case ev.Key == KeyEnter:
+				t.Print("\r\n")
+				return s.b, nil
+			case ev.IsCtrl('c'):
+				s.zero()
+				t.Print("^C\r\n")
+				return nil, ErrInterrupted
+			case ev.IsCtrl('d') && s.runes == 0:
+				t.Print("\r\n")
+				return nil, io.EOF
+			case ev.Key == KeyBackspace, ev.IsCtrl('h'):
+				if s.removeLast() {
+					t.Print(erase)
+				}
+			case ev.IsCtrl('u'):
+				for s.removeLast() {
+					t.Print(erase)
+				}
+			case ev.Key == KeyRune && ev.Mod&(ModCtrl|ModAlt) == 0:
+				add(ev.Rune)
+			}
+		}
+	}
+}
+
+// readPlainSecret reads a line as is when the input is not a terminal.
+func (t *Terminal) readPlainSecret(ctx context.Context, opts SecretOptions) ([]byte, error) {
+	line, err := t.readPlainLine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxLength > 0 {
+		n, runes := 0, 0
+		for n < len(line) && runes < opts.MaxLength {
+			_, size := utf8.DecodeRune(line[n:])
+			n += size
+			runes++
+		}
+		wipe(line[n:])
+		line = line[:n]
+	}
+	return line, nil
+}
+
+// secret collects bytes of a secret, zeroing memory it doesn't use anymore.
+type secret struct {
+	b     []byte
+	runes int
+}
+
+func (s *secret) add(r rune) {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	if len(s.b)+n > cap(s.b) {
+		grown := make([]byte, len(s.b), 2*cap(s.b)+64)
+		copy(grown, s.b)
+		wipe(s.b[:cap(s.b)])
+		s.b = grown
+	}
+	s.b = append(s.b, buf[:n]...)
+	s.runes++
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// removeLast removes the last character and reports whether there has been
+// one.
+func (s *secret) removeLast() bool {
+	if len(s.b) == 0 {
+		return false
+	}
+	_, n := utf8.DecodeLastRune(s.b)
+	for i := len(s.b) - n; i < len(s.b); i++ {
+		s.b[i] = 0
+	}
+	s.b = s.b[:len(s.b)-n]
+	s.runes--
+	return true
+}
+
+// zero clears the bytes collected so far.
+func (s *secret) zero() {
+	wipe(s.b[:cap(s.b)])
+	s.b = s.b[:0]
+	s.runes = 0
+}
+
+// wipe overwrites b with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,182 @@
+package terminal
+
+import (
+	"os"
+	"os/signal"
+)
+
+// Session starts recording the modes turned on with methods of the terminal:
+// raw and cbreak modes, the alternative buffer, the hidden cursor, mouse tracking, focus
+// reporting, bracketed paste, the scroll region, the title and keyboard flags.
+// Restore turns them off in reverse order, and so does receiving SIGINT,
+// SIGTERM or SIGHUP, which is then raised again, so that the program stops
+// the way it would without a session. Sequences printed as strings, such as
+// t.Print(StartAlternativeBuffer()), are not recorded.
+//
+// Deferring Restore right after Session restores the terminal on return and
+// when panicking:
+//
+//	t.Session()
+//	defer t.Restore()
+//	t.StartAlternativeBuffer().SetCursorVisible(false)
+//
+// Calling Session again before Restore does nothing.
+func (t *Terminal) Session() {
+	t.init()
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+	if t.session != nil {
+		return
+	}
+	s := &session{
+		signals: make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+	}
+	t.session = s
+	signal.Notify(s.signals, sessionSignals...)
+	go func() {
+		select {
+		case sig := <-s.signals:
+			t.Restore()
+			raise(sig)
+		case <-s.stop:
+		}
+	}()
+}
+
+// Restore flushes the output, see Flush, turns off the modes recorded since
+// Session in reverse order and ends the session. It returns the first error,
+// but turns off the rest of the modes anyway.
+func (t *Terminal) Restore() error {
+	err := t.Flush()
+	t.sessionMu.Lock()
+	s := t.session
+	t.session = nil
+	t.sessionMu.Unlock()
+	if s == nil {
+		return err
+	}
+	signal.Stop(s.signals)
+	close(s.stop)
+	for i := len(s.modes) - 1; i >= 0; i-- {
+		if e := s.modes[i].restore(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// session is the record of modes kept between Session and Restore.
+type session struct {
+	modes   []sessionMode // In the order they have been turned on
+	signals chan os.Signal
+	stop    chan struct{}
+}
+
+// sessionMode is a mode turned on during the session.
+type sessionMode struct {
+	name    string
+	restore func() error
+}
+
+// record updates the session, if there is one, with the mode changed by
+// function name of terminal_funcs.go called with args.
+func (t *Terminal) record(name string, args []interface{}) {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+	s := t.session
+	if s == nil {
+		return
+	}
+	switch name {
+	case "StartAlternativeBuffer":
+		s.on(t, "alt", EndAlternativeBuffer())
+	case "EndAlternativeBuffer":
+		s.off("alt")
+	case "SetCursorVisible":
+		s.toggle(t, "cursor", !args[0].(bool), SetCursorVisible(true))
+	case "SetScrollRegion":
+		// ESC [ r | Reset the scroll region to the whole screen
+		s.on(t, "scroll", CSI+"r")
+	case "SetMouseButtons":
+		s.toggle(t, name, args[0].(bool), SetMouseButtons(false))
+	case "SetMouseDrag":
+		s.toggle(t, name, args[0].(bool), SetMouseDrag(false))
+	case "SetMouseMotion":
+		s.toggle(t, name, args[0].(bool), SetMouseMotion(false))
+	case "SetSGRMouse":
+		s.toggle(t, name, args[0].(bool), SetSGRMouse(false))
+	case "SetFocusReporting":
+		s.toggle(t, name, args[0].(bool), SetFocusReporting(false))
+	case "SetBracketedPaste":
+		s.toggle(t, name, args[0].(bool), SetBracketedPaste(false))
+	case "SetTitle":
+		if !s.has("title") {
+			// ESC [ 22 ; 0 t | Save the title on the stack
+			t.Print(CSI + "22;0t")
+			// ESC [ 23 ; 0 t | Restore the title saved on the stack
+			s.on(t, "title", CSI+"23;0t")
+		}
+	case "PushKeyboardFlags":
+		s.add("keyboard", func() error {
+			t.modeMu.Lock()
+			defer t.modeMu.Unlock()
+			t.popKeyboardFlags(t.keyboardPushes)
+			return nil
+		})
+	case "EnterCbreak":
+		s.add("cbreak", args[0].(func() error))
+	case "ExitCbreak":
+		s.off("cbreak")
+	case "SetRaw":
+		if args[0].(bool) {
+			s.add("raw", t.ExitRaw)
+		} else {
+			s.off("raw")
+		}
+	}
+}
+
+// on records mode name, which is turned off by printing seq.
+func (s *session) on(t *Terminal, name, seq string) {
+	s.add(name, func() error {
+		_, err := t.Print(seq)
+		return err
+	})
+}
+
+// toggle records mode name as turned on or off.
+func (s *session) toggle(t *Terminal, name string, on bool, seq string) {
+	if on {
+		s.on(t, name, seq)
+	} else {
+		s.off(name)
+	}
+}
+
+// add records mode name unless it has already been turned on.
+func (s *session) add(name string, restore func() error) {
+	if !s.has(name) {
+		s.modes = append(s.modes, sessionMode{name, restore})
+	}
+}
+
+// has reports whether mode name has been turned on.
+func (s *session) has(name string) bool {
+	for _, m := range s.modes {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// off forgets mode name, which has been turned off.
+func (s *session) off(name string) {
+	for i, m := range s.modes {
+		if m.name == name {
+			s.modes = append(s.modes[:i], s.modes[i+1:]...)
+			return
+		}
+	}
+}
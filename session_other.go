@@ -0,0 +1,17 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package terminal
+
+import (
+	"os"
+	"syscall"
+)
+
+// sessionSignals are signals restoring the terminal during a session.
+var sessionSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// raise exits, since signals can't be sent to the process itself.
+func raise(sig os.Signal) {
+	os.Exit(1)
+}
@@ -0,0 +1,21 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sessionSignals are signals restoring the terminal during a session.
+var sessionSignals = []os.Signal{unix.SIGINT, unix.SIGTERM, unix.SIGHUP}
+
+// raise sends sig to the process again, which stops it unless other handlers
+// of the signal are still registered.
+func raise(sig os.Signal) {
+	if s, ok := sig.(unix.Signal); ok {
+		unix.Kill(os.Getpid(), s)
+	}
+}
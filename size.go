@@ -0,0 +1,109 @@
+package terminal
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Size is the size of the terminal window in columns and rows, and in pixels
+// if the system reports it, otherwise PixelWidth and PixelHeight are 0.
+type Size struct {
+	Width, Height           int
+	PixelWidth, PixelHeight int
+}
+
+// CellSize returns the size of a character cell in pixels, or 0, 0 if the
+// pixel size is unknown.
+func (s Size) CellSize() (w, h int) {
+	if s.Width <= 0 || s.Height <= 0 {
+		return 0, 0
+	}
+	return s.PixelWidth / s.Width, s.PixelHeight / s.Height
+}
+
+// Size returns the size of the terminal window, including the pixel size if
+// the system knows it. Terminals usually report it themselves, which
+// QueryPixelSize asks for.
+//
+// If the output is not a terminal, such as when it's piped, or the system
+// reports a zero size, COLUMNS and LINES environment variables are used.
+// Without them, an error is returned for a non-terminal, while GetSize falls
+// back to 80x24. Terminals created with NewTerminalFromStreams don't use the
+// environment, which describes the local terminal.
+func (t *Terminal) Size() (Size, error) {
+	t.init()
+	size, err := t.windowSize()
+	if t.streams != nil {
+		return size, err
+	}
+	if err != nil {
+		if env, ok := envSize(); ok {
+			return env, nil
+		}
+		return Size{}, err
+	}
+	if size.Width == 0 || size.Height == 0 {
+		// Some pseudo terminals are created without a size
+		env, _ := envSize()
+		if size.Width == 0 {
+			size.Width = env.Width
+		}
+		if size.Height == 0 {
+			size.Height = env.Height
+		}
+	}
+	return size, nil
+}
+
+// envSize returns the size set by COLUMNS and LINES environment variables,
+// where a missing one is 0, and reports whether any of them is set.
+func envSize() (Size, bool) {
+	w, errW := strconv.Atoi(os.Getenv("COLUMNS"))
+	h, errH := strconv.Atoi(os.Getenv("LINES"))
+	if errW != nil || w < 0 {
+		w = 0
+	}
+	if errH != nil || h < 0 {
+		h = 0
+	}
+	return Size{Width: w, Height: h}, w > 0 || h > 0
+}
+
+// QueryPixelSize asks the terminal for the size of the text area in pixels
+// with "CSI 14 t" and "CSI 16 t" requests, computing it from the cell size when
+// only that is reported. The terminal is put into raw mode for the time of the
+// query, and keys typed meanwhile are lost, so the result is better kept until
+// ResizeEvent. ErrNoReply means the terminal doesn't report it.
+func (t *Terminal) QueryPixelSize(timeout time.Duration) (w, h int, err error) {
+	// ESC [ 14 t | Report text area size in pixels as ESC [ 4 ; <h> ; <w> t
+	// ESC [ 16 t | Report cell size in pixels as ESC [ 6 ; <h> ; <w> t
+	seqs, err := t.exchange(CSI+"14t"+CSI+"16t"+requestPrimaryAttributes, timeout, isPrimaryAttributes)
+	var cellW, cellH int
+	for _, seq := range seqs {
+		if len(seq) < 4 || seq[1] != '[' || seq[len(seq)-1] != 't' {
+			continue
+		}
+		p := parseParams(seq[2 : len(seq)-1])
+		if len(p) != 3 {
+			continue
+		}
+		switch p[0] {
+		case 4:
+			h, w = p[1], p[2]
+		case 6:
+			cellH, cellW = p[1], p[2]
+		}
+	}
+	if (w == 0 || h == 0) && cellW > 0 && cellH > 0 {
+		cols, rows := t.GetSize()
+		w, h = cellW*cols, cellH*rows
+	}
+	if w > 0 && h > 0 {
+		return w, h, nil
+	}
+	if err == nil {
+		err = ErrNoReply
+	}
+	return 0, 0, err
+}
@@ -0,0 +1,14 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package terminal
+
+import "os"
+
+// windowSize is not supported on this platform.
+func windowSize(f *os.File) (Size, error) {
+	if f == nil {
+		return Size{}, &NotTerminalError{}
+	}
+	return Size{}, &NotTerminalError{Name: f.Name()}
+}
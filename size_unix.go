@@ -0,0 +1,27 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// windowSize returns the size of the terminal window f is attached to.
+func windowSize(f *os.File) (Size, error) {
+	if f == nil {
+		return Size{}, &NotTerminalError{}
+	}
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return Size{}, err
+	}
+	return Size{
+		Width:       int(ws.Col),
+		Height:      int(ws.Row),
+		PixelWidth:  int(ws.Xpixel),
+		PixelHeight: int(ws.Ypixel),
+	}, nil
+}
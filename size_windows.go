@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// windowSize returns the size of the console window f is attached to. The
+// console doesn't know the pixel size.
+func windowSize(f *os.File) (Size, error) {
+	if f == nil {
+		return Size{}, &NotTerminalError{}
+	}
+	w, h, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return Size{}, err
+	}
+	return Size{Width: w, Height: h}, nil
+}
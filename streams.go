@@ -0,0 +1,101 @@
+package terminal
+
+import (
+	"io"
+	"sync"
+)
+
+// StreamOptions describe the terminal on the other end of the streams passed
+// to NewTerminalFromStreams, which can't be detected.
+type StreamOptions struct {
+	// IsTerminal tells whether there is a terminal on the other end, such as
+	// when an SSH client has sent a "pty-req" request. Otherwise sequences are
+	// stripped from the output, and input modes and queries fail with
+	// NotTerminalError.
+	IsTerminal bool
+	// Size is the size of the terminal window. SetSize updates it. While it's
+	// zero, Size fails with NotTerminalError and GetSize returns 80x24, as
+	// COLUMNS and LINES describe the local terminal.
+	Size Size
+	// Term is the TERM of the terminal, such as the one sent in "pty-req",
+	// which the color profile is guessed from unless Profile is set.
+	Term string
+	// Profile is the color profile of the terminal. If it's 0, it's guessed
+	// from Term the same way DetectColorProfile does it, which is ANSI16 when
+	// Term is empty. SetColorMode(ColorNever) strips colors.
+	Profile ColorProfile
+}
+
+// NewTerminalFromStreams returns a terminal reading the user input from in and
+// writing to out, such as the channel of an SSH session:
+//
+//	t := terminal.NewTerminalFromStreams(channel, channel, terminal.StreamOptions{
+//		IsTerminal: true,
+//		Size:       terminal.Size{Width: int(pty.Width), Height: int(pty.Height)},
+//		Term:       pty.Term,
+//	})
+//
+// The terminal on the other end is expected to be in raw mode already, which
+// is what SSH clients do once they request a pseudo terminal, so EnterRaw and
+// EnterCbreak don't change anything. Environment variables, such as NO_COLOR,
+// are ignored, since they describe the local terminal.
+func NewTerminalFromStreams(in io.Reader, out io.Writer, opts StreamOptions) *Terminal {
+	profile := opts.Profile
+	if profile == NoColor {
+		profile = profileFromTerm(opts.Term)
+	}
+	t := &Terminal{
+		out:     out,
+		isTerm:  opts.IsTerminal,
+		profile: profile,
+		streams: &streams{in: in, size: opts.Size},
+	}
+	t.init()
+	return t
+}
+
+// SetSize sets the window size of a terminal created with
+// NewTerminalFromStreams, such as on an SSH "window-change" request. Once the
+// size stops changing, it's reported to OnResize handlers, ResizeEvents and as
+// ResizeEvent the same way local window resizes are. It does nothing for other
+// terminals, which learn about the size from the system.
+func (t *Terminal) SetSize(size Size) {
+	s := t.streams
+	if s == nil {
+		return
+	}
+	w := t.resizeWatcher()
+	s.mu.Lock()
+	s.size = size
+	s.mu.Unlock()
+	notifyChange(w.changes)
+}
+
+// streams is the connection of a terminal created with NewTerminalFromStreams.
+type streams struct {
+	in   io.Reader
+	mu   sync.Mutex
+	size Size
+}
+
+// windowSize returns the size of the terminal window.
+func (t *Terminal) windowSize() (Size, error) {
+	s := t.streams
+	if s == nil {
+		return windowSize(t.f)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == (Size{}) {
+		return Size{}, &NotTerminalError{}
+	}
+	return s.size, nil
+}
+
+// source returns the input.
+func (t *Terminal) source() io.Reader {
+	if t.streams != nil {
+		return t.streams.in
+	}
+	return t.in
+}
@@ -0,0 +1,140 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Underline is a style of underlining text. Anything but UnderlineSingle is an
+// extension which is supported by fewer terminals, others usually fall back
+// to a single underline.
+type Underline int
+
+const (
+	UnderlineNone Underline = iota
+	UnderlineSingle
+	UnderlineDouble
+	UnderlineCurly
+	UnderlineDotted
+	UnderlineDashed
+)
+
+// param returns SGR parameter turning on the underline style.
+func (u Underline) param() string {
+	switch u {
+	case UnderlineNone:
+		return "24"
+	case UnderlineDouble:
+		return "4:2"
+	case UnderlineCurly:
+		return "4:3"
+	case UnderlineDotted:
+		return "4:4"
+	case UnderlineDashed:
+		return "4:5"
+	}
+	return "4"
+}
+
+// Style is a set of text formatting attributes. The zero Style doesn't change
+// anything.
+//
+//	warn := terminal.Style{Fg: terminal.Yellow, Bold: true}
+//	t.PrintStyled(warn, "warning")
+//	fmt.Println(warn.Render("warning") + ": disk is almost full")
+type Style struct {
+	Fg, Bg         Color
+	Bold           bool
+	Dim            bool
+	Italic         bool
+	Underline      Underline
+	UnderlineColor Color
+	Blink          bool
+	Reverse        bool
+	Hidden         bool
+	Strikethrough  bool
+	Overline       bool
+}
+
+// Render returns text surrounded by sequences setting the style and then
+// restoring only the attributes that the style has changed, unlike Reset.
+func (s Style) Render(text string) string {
+	return s.Sequence() + text + Style{}.since(s)
+}
+
+// Sprintf formats according to a format specifier and renders the result with
+// the style.
+func (s Style) Sprintf(format string, a ...interface{}) string {
+	return s.Render(fmt.Sprintf(format, a...))
+}
+
+// Sequence returns a sequence turning on the style, assuming no formatting has
+// been applied before.
+func (s Style) Sequence() string {
+	return s.since(Style{})
+}
+
+// Transition returns the shortest sequence changing formatting from style s to
+// next, or an empty string if nothing has to change.
+func (s Style) Transition(next Style) string {
+	return next.since(s)
+}
+
+// since returns the sequence turning prev style into s.
+func (s Style) since(prev Style) string {
+	var p []string
+	if prev.Bold && !s.Bold || prev.Dim && !s.Dim {
+		// Bold and dim are both turned off by the same 22.
+		p = append(p, "22")
+		prev.Bold, prev.Dim = false, false
+	}
+	if s.Bold && !prev.Bold {
+		p = append(p, "1")
+	}
+	if s.Dim && !prev.Dim {
+		p = append(p, "2")
+	}
+	p = appendFlag(p, prev.Italic, s.Italic, "3", "23")
+	if s.Underline != prev.Underline {
+		p = append(p, s.Underline.param())
+	}
+	p = appendFlag(p, prev.Blink, s.Blink, "5", "25")
+	p = appendFlag(p, prev.Reverse, s.Reverse, "7", "27")
+	p = appendFlag(p, prev.Hidden, s.Hidden, "8", "28")
+	p = appendFlag(p, prev.Strikethrough, s.Strikethrough, "9", "29")
+	p = appendFlag(p, prev.Overline, s.Overline, "53", "55")
+	p = appendColorChange(p, prev.Fg, s.Fg, 30)
+	p = appendColorChange(p, prev.Bg, s.Bg, 40)
+	p = appendColorChange(p, prev.UnderlineColor, s.UnderlineColor, 50)
+	if len(p) == 0 {
+		return ""
+	}
+	return CSI + strings.Join(p, ";") + "m"
+}
+
+func appendFlag(p []string, was, is bool, on, off string) []string {
+	switch {
+	case is && !was:
+		return append(p, on)
+	case was && !is:
+		return append(p, off)
+	}
+	return p
+}
+
+// appendColorChange appends parameters changing color prev to c, where base is
+// 30 for foreground, 40 for background and 50 for underline.
+func appendColorChange(p []string, prev, c Color, base int) []string {
+	switch {
+	case c == prev:
+		return p
+	case !c.IsSet():
+		return append(p, DefaultColor.params(base))
+	}
+	return append(p, c.params(base))
+}
+
+// PrintStyled outputs a using fmt.Sprint rendered with the style.
+func (t *Terminal) PrintStyled(style Style, a ...interface{}) (n int, err error) {
+	return t.Print(style.Render(fmt.Sprint(a...)))
+}
@@ -4,7 +4,6 @@ package terminal
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strings"
 	"sync"
@@ -12,6 +11,7 @@ import (
 	"runtime"
 
 	"github.com/mattn/go-colorable"
+	"github.com/zzwx/terminal/terminfo"
 	"golang.org/x/term"
 )
 
@@ -31,16 +31,26 @@ const (
 	ESC = "\x1b"    // ESC consists of one 0x1b symbol.
 	CSI = ESC + "[" // CSI stands for Control Sequence Introducer, used in the majority of sequences.
 
-	// [X] Reset       = CSI + "0m" - see ResetAll(), Reset
-	// [X] Bright      = CSI + "1m" - see SetBright()
-	// [?] Dim         = CSI + "2m" // Dim doesn't work in Windows. Use SetBright instead.
-	// [X] NoBright    = CSI + "22m" - see SetBright()
-	// [X] Underline   = CSI + "4m"  // - see SetUnderline()
-	// [X] NoUnderline = CSI + "24m" // - see SetUnderline()
-	// [?] Blink       = CSI + "5m" // Blink doesn't seem to be supported in Windows.
-	// [X] Swap        = CSI + "7m" - see Swap()
-	// [?] Hidden      = CSI + "8m" - Doesn't seem to work under Windows
-	// [X] CancelSwap  = CSI + "27m" - see CancelSwap()
+	// [X] Reset           = CSI + "0m" - see ResetAll(), Reset
+	// [X] Bright          = CSI + "1m" - see SetBright()
+	// [X] Dim             = CSI + "2m" - see SetDim(). Dim doesn't work in Windows console. Use SetBright instead.
+	// [X] NoBright        = CSI + "22m" - see SetBright(), SetDim()
+	// [X] Italic          = CSI + "3m" - see SetItalic()
+	// [X] NoItalic        = CSI + "23m" - see SetItalic()
+	// [X] Underline       = CSI + "4m"  // - see SetUnderline()
+	// [X] DoubleUnderline = CSI + "4:2m"  // - see SetUnderlineStyle()
+	// [X] CurlyUnderline  = CSI + "4:3m"  // - see SetUnderlineStyle()
+	// [X] NoUnderline     = CSI + "24m" // - see SetUnderline()
+	// [X] Blink           = CSI + "5m" - see SetTextBlink(). Blink doesn't seem to be supported in Windows.
+	// [X] NoBlink         = CSI + "25m" - see SetTextBlink()
+	// [X] Swap            = CSI + "7m" - see Swap()
+	// [X] Hidden          = CSI + "8m" - see SetHidden(). Doesn't seem to work under Windows
+	// [X] NoHidden        = CSI + "28m" - see SetHidden()
+	// [X] Strikethrough   = CSI + "9m" - see SetStrikethrough()
+	// [X] NoStrikethrough = CSI + "29m" - see SetStrikethrough()
+	// [X] CancelSwap      = CSI + "27m" - see CancelSwap()
+	// [X] Overline        = CSI + "53m" - see SetOverline()
+	// [X] NoOverline      = CSI + "55m" - see SetOverline()
 
 	FgBlack   = CSI + "30m"
 	FgRed     = CSI + "31m"
@@ -53,8 +63,9 @@ const (
 
 	// [X] 38 ; 2 ; <r> ; <g> ; <b>m	 | Set foreground color to RGB value specified in <r>, <g>, <b> parameters*
 	// [X] 48 ; 2 ; <r> ; <g> ; <b>m   | Set background color to RGB value specified in <r>, <g>, <b> parameters*
+	// [X] 38 ; 5 ; <s> m               | Set foreground color to <s> index in 256 color table - see Fg256()
 	// [ ] 38m | Applies extended color value to the foreground (see details below)
-	// [ ] 39m | Applies only the foreground portion of the defaults (see 0)
+	// [X] 39m | Applies only the foreground portion of the defaults (see 0) - see DefaultFg()
 
 	BgBlack   = CSI + "40m"
 	BgRed     = CSI + "41m"
@@ -65,8 +76,13 @@ const (
 	BgCyan    = CSI + "46m"
 	BgWhite   = CSI + "47m"
 
+	// [X] 48 ; 5 ; <s> m // Set background color to <s> index in 256 color table - see Bg256()
 	// [ ] 48m // Applies extended color value to the background (see details below)
-	// [ ] 49m // Applies only the background portion of the defaults (see 0)
+	// [X] 49m // Applies only the background portion of the defaults (see 0) - see DefaultBg()
+
+	// [X] 58 ; 2 ; <r> ; <g> ; <b>m // Set underline color to RGB value - see UnderlineRGB()
+	// [X] 58 ; 5 ; <s> m // Set underline color to <s> index in 256 color table - see Underline256()
+	// [X] 59m // Applies default underline color - see DefaultUnderlineColor()
 
 	FgHiBlack   = CSI + "90m"
 	FgHiRed     = CSI + "91m"
@@ -166,16 +182,53 @@ func BgColors() []string {
 // formatting.
 type Terminal struct {
 	f      *os.File
+	in     *os.File
 	out    io.Writer
 	once   sync.Once
-	raw    *term.State
 	isTerm bool
+
+	modeMu         sync.Mutex // Guards raw mode and keyboard flags
+	raw            *term.State
+	rawFd          int
+	keyboardPushes int // Number of PushKeyboardFlags to pop on restore
+
+	sequences bool // Whether the output accepts control sequences
+	mode      ColorMode
+	profile   ColorProfile
+	filter    *filterWriter
+
+	capsOnce sync.Once
+	caps     Capabilities
+
+	ti *terminfo.Terminfo // Optional source of sequences, see SetTerminfo
+
+	resizeOnce sync.Once
+	resize     *resizeWatcher
+
+	streams *streams // Set by NewTerminalFromStreams
+
+	sessionMu sync.Mutex
+	session   *session // Modes to restore, see Session
+
+	inputMu    sync.Mutex
+	reader     *inputReader // Started by the first ReadKey or Keys
+	lineReader *lineReader  // Started by reading a line from a non-terminal
+	escTimeout int64        // time.Duration, accessed atomically
+	maxPaste   int64        // Accessed atomically
 }
 
 func (t *Terminal) Write(p []byte) (n int, err error) {
 	return t.Print(string(p)) // TODO: Thoroughly test whether incomplete utf bytes could cause an issue
 }
 
+// Flush writes out an incomplete escape sequence held back from the previous
+// writes until the rest of it arrives, which only happens when a sequence is
+// split between several writes. Restore flushes the output too.
+func (t *Terminal) Flush() error {
+	t.init()
+	return t.filter.Flush()
+}
+
 // NewTerminal returns a new Terminal instance attached
 // to specified file, typically os.Stdout.
 //
@@ -198,69 +251,127 @@ func syncFileFinalizer(t *Terminal) {
 	}
 }
 
+// OverrideOut makes the terminal write to out, which is assumed to accept
+// sequences. See NewTerminalFromStreams to describe the terminal on the other
+// end instead.
 func (t *Terminal) OverrideOut(out io.Writer) {
-	t.out = out
-}
-
-// SetRaw puts the terminal connection into raw mode or back.
-func (t *Terminal) SetRaw(raw bool) {
-	t.init()
-	if !t.IsTerminal() {
+	if t.filter == nil {
+		// Not initialized yet
+		t.out = out
 		return
 	}
-	if t.raw != nil {
-		return
+	t.filter.SetWriter(out)
+	if t.streams == nil {
+		t.sequences = true
 	}
+	t.applyColorMode()
+}
+
+// SetRaw puts the terminal connection into raw mode or back. Turning raw mode
+// off also pops keyboard flags pushed with PushKeyboardFlags. Errors are
+// ignored, see EnterRaw and ExitRaw for them.
+func (t *Terminal) SetRaw(raw bool) {
 	if raw {
-		if t.raw == nil { // Already raw
-			r, err := term.MakeRaw(int(t.f.Fd()))
-			if err != nil {
-				log.Fatalf("can't make terminal raw due to: %v", err)
-			}
-			t.raw = r
-		}
+		t.EnterRaw()
 	} else {
-		if t.raw != nil { // Not been set to raw
-			err := term.Restore(int(t.f.Fd()), t.raw)
-			if err != nil {
-				log.Fatalf("can't restore terminal from raw state due to: %v", err)
-			}
-			t.raw = nil
-		}
+		t.ExitRaw()
 	}
 }
 
 func (t *Terminal) init() {
 	t.once.Do(func() {
-		if t.out == nil {
+		if t.in == nil && t.streams == nil {
+			t.in = os.Stdin
+		}
+		switch {
+		case t.streams != nil:
+			// Everything is known from NewTerminalFromStreams.
+			t.sequences = t.isTerm
+		case t.out != nil:
+			// Output has been overridden, nothing is known about it.
+			t.profile = TrueColor
+			t.sequences = true
+		default:
 			if t.f == nil {
 				t.f = os.Stdout
 			}
 			//if isatty.IsTerminal(t.f.Fd()) {
-			if IsTerminal(int(t.f.Fd())) {
-				if runtime.GOOS == "windows" /*&& os.Getenv("TERM") != ""*/ {
-					// TODO: Find a way to say if this windows version already supports terminal commands
-					err := EnableVirtualTerminalProcessing(t.f, true)
-					if err != nil {
-						log.Fatalf("Can't enable virtual terminal processing due to %v", err)
-					}
+			t.isTerm = IsTerminal(int(t.f.Fd()))
+			t.sequences = t.isTerm || os.Getenv("FORCE_TERMINAL_SEQUENCES") == "1"
+			if t.isTerm && runtime.GOOS == "windows" /*&& os.Getenv("TERM") != ""*/ {
+				// TODO: Find a way to say if this windows version already supports terminal commands
+				err := EnableVirtualTerminalProcessing(t.f, true)
+				if err == nil {
 					t.out = t.f
-					t.isTerm = true
 				} else {
+					// Older consoles, sequences are translated
 					t.out = colorable.NewColorable(t.f)
 				}
+			} else {
+				t.out = colorable.NewColorable(t.f)
 			}
-			if t.out == nil {
-				if os.Getenv("FORCE_TERMINAL_SEQUENCES") == "1" {
-					t.out = colorable.NewColorable(t.f)
-				} else {
-					t.out = colorable.NewNonColorable(t.f)
-				}
-			}
+			t.profile = DetectColorProfile()
 		}
+		t.filter = &filterWriter{w: t.out}
+		t.out = t.filter
+		t.applyColorMode()
 	})
 }
 
+// applyColorMode sets up the output filter according to the color mode, color
+// profile and whether the output accepts sequences at all.
+func (t *Terminal) applyColorMode() {
+	mode := t.mode
+	if mode == ColorAuto && t.streams == nil {
+		mode = DetectColorMode()
+	}
+	switch {
+	case mode == ColorNever:
+		t.filter.Set(NoColor, t.sequences, t.sequences)
+	case mode == ColorAlways:
+		profile := t.profile
+		if profile == NoColor {
+			profile = ANSI16
+		}
+		t.filter.Set(profile, true, t.sequences)
+	case t.sequences:
+		t.filter.Set(t.profile, true, true)
+	default:
+		t.filter.Set(NoColor, false, false)
+	}
+}
+
+// ColorProfile reports the color profile that color sequences are converted to
+// before reaching the output. NoColor means colors are being stripped.
+func (t *Terminal) ColorProfile() ColorProfile {
+	t.init()
+	return t.filter.Profile()
+}
+
+// SetColorProfile overrides the color profile detected during initialization.
+// Truecolor and 256-color sequences are converted to the nearest colors
+// supported by p.
+func (t *Terminal) SetColorProfile(p ColorProfile) {
+	t.init()
+	t.profile = p
+	t.applyColorMode()
+}
+
+// SetColorMode sets whether colors are output. ColorAuto, which is the default,
+// outputs colors only to a terminal, unless the user has asked otherwise using
+// NO_COLOR, CLICOLOR or CLICOLOR_FORCE environment variables. ColorAlways and
+// ColorNever override both the environment and the terminal detection, which
+// is what a --color=always|never command line flag is expected to do.
+//
+// Colors are stripped independently of other sequences, so ColorNever still
+// allows cursor movement, for instance in SameLinePrintf, if the output is a
+// terminal.
+func (t *Terminal) SetColorMode(mode ColorMode) {
+	t.init()
+	t.mode = mode
+	t.applyColorMode()
+}
+
 func (t *Terminal) Printf(format string, a ...interface{}) (n int, err error) {
 	t.init()
 	return fmt.Fprintf(t.out, format, a...)
@@ -306,13 +417,22 @@ func (t *Terminal) IsTerminal() bool {
 	return t.isTerm
 }
 
-// GetSize reports current (width, height) of the viewport\
-// or 80,24 if it can't retrieve it
+// GetSize reports current (width, height) of the viewport,
+// COLUMNS and LINES if the output is not a terminal and the terminal
+// isn't created with NewTerminalFromStreams,
+// or 80,24 if it can't retrieve it. See Size for errors.
 func (t *Terminal) GetSize() (w, h int) {
 	t.init()
-	w, h, err := term.GetSize(int(t.f.Fd()))
-	if err != nil {
-		w, h = 80, 24
+	size, err := t.windowSize()
+	if err != nil && t.streams == nil {
+		size, _ = envSize()
+	}
+	w, h = size.Width, size.Height
+	if w <= 0 {
+		w = 80
+	}
+	if h <= 0 {
+		h = 24
 	}
 	return
 }
@@ -322,6 +442,7 @@ func (t *Terminal) GetSize() (w, h int) {
 func (t *Terminal) SetTitle(title string) {
 	t.init()
 	if t.IsTerminal() {
+		t.record("SetTitle", nil)
 		// ESC ] 0 ; <string> BEL
 		t.Printf(ESC + "]0;" + strings.ReplaceAll(title, "\x07", "") + "\x07")
 	}
@@ -333,5 +454,5 @@ func (t *Terminal) SetTitle(title string) {
 //
 // For quick concatenations use terminal.Reset.
 func (t *Terminal) Reset() {
-	t.Printf(Reset)
+	t.Print(t.sequence("Reset", Reset))
 }
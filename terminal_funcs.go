@@ -169,6 +169,63 @@ func SetUnderline(on bool) string {
 	return CSI + "24m"
 }
 
+// SetDim sets dim / faint flag to foreground color. Turning it off also turns
+// off SetBright, since both are reset by the same sequence.
+func SetDim(on bool) string {
+	if on {
+		return CSI + "2m"
+	}
+	return CSI + "22m"
+}
+
+// SetItalic sets italic font.
+func SetItalic(on bool) string {
+	if on {
+		return CSI + "3m"
+	}
+	return CSI + "23m"
+}
+
+// SetUnderlineStyle sets font with one of the extended underline styles, such
+// as curly or double. UnderlineNone turns underline off. Terminals that don't
+// support extended styles usually fall back to a single underline.
+func SetUnderlineStyle(style Underline) string {
+	// ESC [ 4 : <n> m
+	return CSI + style.param() + "m"
+}
+
+// SetTextBlink sets text blinking on / off. For cursor blinking see SetBlinking.
+func SetTextBlink(on bool) string {
+	if on {
+		return CSI + "5m"
+	}
+	return CSI + "25m"
+}
+
+// SetHidden makes text invisible, while still occupying space.
+func SetHidden(on bool) string {
+	if on {
+		return CSI + "8m"
+	}
+	return CSI + "28m"
+}
+
+// SetStrikethrough sets font with a horizontal line through the middle.
+func SetStrikethrough(on bool) string {
+	if on {
+		return CSI + "9m"
+	}
+	return CSI + "29m"
+}
+
+// SetOverline sets font with a line above the text.
+func SetOverline(on bool) string {
+	if on {
+		return CSI + "53m"
+	}
+	return CSI + "55m"
+}
+
 // SetScrollRegion sets the region for scrolling using ScrollBy, by specifying
 // top and bottom fixed areas. Scrolling also happens if \n is printed at the
 // last line of the scroll region or MoveUpScroll at the top of it, filling the
@@ -257,7 +314,7 @@ func StartAlternativeBuffer() string {
 // unnecessary. If the program interrupts in the middle, it seems necessary
 // to implicitly call EndAlternativeBuffer, otherwise the console will print
 // out the prompt with the alternative buffer settings, and at least in case of cmd.exe
-// continues typing with these settings.
+// continues typing with these settings. Terminal.Session takes care of that.
 func EndAlternativeBuffer() string {
 	return CSI + "?1049l"
 }
@@ -331,10 +388,130 @@ func CancelSwap() string {
 	return CSI + "27m"
 }
 
+// FgRGB sets foreground color to RGB value. Components are limited to 0–255.
+// Terminals not supporting true colors get the nearest color, see
+// ColorProfile.
 func FgRGB(r, g, b int) string {
-	return CSI + "38;2;" + strconv.Itoa(r) + ";" + strconv.Itoa(g) + ";" + strconv.Itoa(b) + "m"
+	return CSI + "38;2;" + strconv.Itoa(clamp8(r)) + ";" + strconv.Itoa(clamp8(g)) + ";" + strconv.Itoa(clamp8(b)) + "m"
 }
 
+// BgRGB sets background color to RGB value. Components are limited to 0–255.
+// Terminals not supporting true colors get the nearest color, see
+// ColorProfile.
 func BgRGB(r, g, b int) string {
-	return CSI + "48;2;" + strconv.Itoa(r) + ";" + strconv.Itoa(g) + ";" + strconv.Itoa(b) + "m"
+	return CSI + "48;2;" + strconv.Itoa(clamp8(r)) + ";" + strconv.Itoa(clamp8(g)) + ";" + strconv.Itoa(clamp8(b)) + "m"
+}
+
+// Fg256 sets foreground color to n-th color (0–255) of the 256-color palette.
+// See Palette256 for colors and RGBTo256 to pick one.
+func Fg256(n int) string {
+	if n < 0 || n > 255 {
+		return ""
+	}
+	// ESC [ 38 ; 5 ; <s> m | Set foreground color to <s> index in 88 or 256 color table*
+	return CSI + "38;5;" + strconv.Itoa(n) + "m"
+}
+
+// Bg256 sets background color to n-th color (0–255) of the 256-color palette.
+// See Palette256 for colors and RGBTo256 to pick one.
+func Bg256(n int) string {
+	if n < 0 || n > 255 {
+		return ""
+	}
+	// ESC [ 48 ; 5 ; <s> m | Set background color to <s> index in 88 or 256 color table*
+	return CSI + "48;5;" + strconv.Itoa(n) + "m"
+}
+
+// DefaultFg restores foreground color to the default, without affecting other
+// attributes unlike Reset.
+func DefaultFg() string {
+	return CSI + "39m"
+}
+
+// DefaultBg restores background color to the default, without affecting other
+// attributes unlike Reset.
+func DefaultBg() string {
+	return CSI + "49m"
+}
+
+// UnderlineRGB sets color of the underline to RGB value. Components are limited
+// to 0–255. By default, underline has the color of the text.
+func UnderlineRGB(r, g, b int) string {
+	return CSI + "58;2;" + strconv.Itoa(clamp8(r)) + ";" + strconv.Itoa(clamp8(g)) + ";" + strconv.Itoa(clamp8(b)) + "m"
+}
+
+// Underline256 sets color of the underline to n-th color (0–255) of the
+// 256-color palette.
+func Underline256(n int) string {
+	if n < 0 || n > 255 {
+		return ""
+	}
+	return CSI + "58;5;" + strconv.Itoa(n) + "m"
+}
+
+// DefaultUnderlineColor restores underline color to the color of the text.
+func DefaultUnderlineColor() string {
+	return CSI + "59m"
+}
+
+// SetMouseButtons turns on / off reporting of mouse button presses and
+// releases, including the wheel, as MouseEvent. Combine it with SetSGRMouse
+// for terminals wider than 223 columns.
+func SetMouseButtons(on bool) string {
+	if on {
+		// ESC [ ? 1000 h | Send mouse X & Y on button press and release
+		return CSI + "?1000h"
+	}
+	return CSI + "?1000l"
+}
+
+// SetMouseDrag turns on / off reporting of mouse motion while a button is held,
+// in addition to what SetMouseButtons reports.
+func SetMouseDrag(on bool) string {
+	if on {
+		// ESC [ ? 1002 h | Use Cell Motion Mouse Tracking
+		return CSI + "?1002h"
+	}
+	return CSI + "?1002l"
+}
+
+// SetMouseMotion turns on / off reporting of all mouse motion, even without
+// buttons held, in addition to what SetMouseButtons reports.
+func SetMouseMotion(on bool) string {
+	if on {
+		// ESC [ ? 1003 h | Use All Motion Mouse Tracking
+		return CSI + "?1003h"
+	}
+	return CSI + "?1003l"
+}
+
+// SetSGRMouse turns on / off the extended format of mouse reports, which has no
+// limit on coordinates and tells which button is released. It doesn't enable
+// reporting by itself.
+func SetSGRMouse(on bool) string {
+	if on {
+		// ESC [ ? 1006 h | Enable SGR Mouse Mode
+		return CSI + "?1006h"
+	}
+	return CSI + "?1006l"
+}
+
+// SetBracketedPaste turns on / off marking of pasted text, so that it is read
+// as a single PasteEvent instead of separate keys.
+func SetBracketedPaste(on bool) string {
+	if on {
+		// ESC [ ? 2004 h | Enable bracketed paste mode
+		return CSI + "?2004h"
+	}
+	return CSI + "?2004l"
+}
+
+// SetFocusReporting turns on / off reporting of the terminal window gaining and
+// losing focus as FocusEvent.
+func SetFocusReporting(on bool) string {
+	if on {
+		// ESC [ ? 1004 h | Send ESC [ I and ESC [ O on focus in and out
+		return CSI + "?1004h"
+	}
+	return CSI + "?1004l"
 }
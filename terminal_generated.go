@@ -5,14 +5,14 @@ package terminal
 // MoveByX moves cursor position by x difference. Negative means left, positive -
 // right. Never passes the edges.
 func (t *Terminal) MoveByX(xDiff int) *Terminal {
-	t.Print(MoveByX(xDiff))
+	t.Print(t.sequence("MoveByX", MoveByX(xDiff), xDiff))
 	return t
 }
 
 // MoveByY moves cursor position by yDiff difference. Negative means up, positive
 // down. Doesn't cause scrolling.
 func (t *Terminal) MoveByY(yDiff int) *Terminal {
-	t.Print(MoveByY(yDiff))
+	t.Print(t.sequence("MoveByY", MoveByY(yDiff), yDiff))
 	return t
 }
 
@@ -20,84 +20,129 @@ func (t *Terminal) MoveByY(yDiff int) *Terminal {
 // Upon reaching the top of the screen it begins appending empty
 // lines with the current background color.
 func (t *Terminal) MoveUpScroll() *Terminal {
-	t.Print(MoveUpScroll())
+	t.Print(t.sequence("MoveUpScroll", MoveUpScroll()))
 	return t
 }
 
 // MoveNextLineBy moves the cursor down by amount,
 // to the first column, without scrolling.
 func (t *Terminal) MoveNextLineBy(amount int) *Terminal {
-	t.Print(MoveNextLineBy(amount))
+	t.Print(t.sequence("MoveNextLineBy", MoveNextLineBy(amount), amount))
 	return t
 }
 
 // MovePreviousLineBy moves the cursor up by amount,
 // to the first column, without scrolling.
 func (t *Terminal) MovePreviousLineBy(amount int) *Terminal {
-	t.Print(MovePreviousLineBy(amount))
+	t.Print(t.sequence("MovePreviousLineBy", MovePreviousLineBy(amount), amount))
 	return t
 }
 
 // MoveToXY moves cursor to absolute x.y. Accepts numbers from (0,0) as top left
 // corner.
 func (t *Terminal) MoveToXY(x, y int) *Terminal {
-	t.Print(MoveToXY(x, y))
+	t.Print(t.sequence("MoveToXY", MoveToXY(x, y), x, y))
 	return t
 }
 
 // MoveTopLeft moves the cursor to absolute (0,0) corner of the screen, equals to MoveToXY(0,0).
 func (t *Terminal) MoveTopLeft() *Terminal {
-	t.Print(MoveTopLeft())
+	t.Print(t.sequence("MoveTopLeft", MoveTopLeft()))
 	return t
 }
 
 // MoveToX moves cursor to absolute x column, starting from 0 as left-most column.
 func (t *Terminal) MoveToX(x int) *Terminal {
-	t.Print(MoveToX(x))
+	t.Print(t.sequence("MoveToX", MoveToX(x), x))
 	return t
 }
 
 // MoveToY moves cursor to absolute y row, starting from 0 as left-most column.
 func (t *Terminal) MoveToY(y int) *Terminal {
-	t.Print(MoveToY(y))
+	t.Print(t.sequence("MoveToY", MoveToY(y), y))
 	return t
 }
 
 // SavePos issues terminal command to save cursor position for upcoming
 // RestorePos.
 func (t *Terminal) SavePos() *Terminal {
-	t.Print(SavePos())
+	t.Print(t.sequence("SavePos", SavePos()))
 	return t
 }
 
 // RestorePos issues terminal command to restore cursor position saved previously
 // using SavePos.
 func (t *Terminal) RestorePos() *Terminal {
-	t.Print(RestorePos())
+	t.Print(t.sequence("RestorePos", RestorePos()))
 	return t
 }
 
 // SetCursorVisible sets cursor visibility.
 func (t *Terminal) SetCursorVisible(visible bool) *Terminal {
-	t.Print(SetCursorVisible(visible))
+	t.Print(t.sequence("SetCursorVisible", SetCursorVisible(visible), visible))
 	return t
 }
 
 // SetBlinking sets cursor blinking on / off.
 func (t *Terminal) SetBlinking(on bool) *Terminal {
-	t.Print(SetBlinking(on))
+	t.Print(t.sequence("SetBlinking", SetBlinking(on), on))
 	return t
 }
 
 // SetBright sets bright / bold flag to foreground color.
 func (t *Terminal) SetBright(on bool) *Terminal {
-	t.Print(SetBright(on))
+	t.Print(t.sequence("SetBright", SetBright(on), on))
 	return t
 }
 
 // SetUnderline sets font with underline.
 func (t *Terminal) SetUnderline(on bool) *Terminal {
-	t.Print(SetUnderline(on))
+	t.Print(t.sequence("SetUnderline", SetUnderline(on), on))
+	return t
+}
+
+// SetDim sets dim / faint flag to foreground color. Turning it off also turns
+// off SetBright, since both are reset by the same sequence.
+func (t *Terminal) SetDim(on bool) *Terminal {
+	t.Print(t.sequence("SetDim", SetDim(on), on))
+	return t
+}
+
+// SetItalic sets italic font.
+func (t *Terminal) SetItalic(on bool) *Terminal {
+	t.Print(t.sequence("SetItalic", SetItalic(on), on))
+	return t
+}
+
+// SetUnderlineStyle sets font with one of the extended underline styles, such
+// as curly or double. UnderlineNone turns underline off. Terminals that don't
+// support extended styles usually fall back to a single underline.
+func (t *Terminal) SetUnderlineStyle(style Underline) *Terminal {
+	t.Print(t.sequence("SetUnderlineStyle", SetUnderlineStyle(style), style))
+	return t
+}
+
+// SetTextBlink sets text blinking on / off. For cursor blinking see SetBlinking.
+func (t *Terminal) SetTextBlink(on bool) *Terminal {
+	t.Print(t.sequence("SetTextBlink", SetTextBlink(on), on))
+	return t
+}
+
+// SetHidden makes text invisible, while still occupying space.
+func (t *Terminal) SetHidden(on bool) *Terminal {
+	t.Print(t.sequence("SetHidden", SetHidden(on), on))
+	return t
+}
+
+// SetStrikethrough sets font with a horizontal line through the middle.
+func (t *Terminal) SetStrikethrough(on bool) *Terminal {
+	t.Print(t.sequence("SetStrikethrough", SetStrikethrough(on), on))
+	return t
+}
+
+// SetOverline sets font with a line above the text.
+func (t *Terminal) SetOverline(on bool) *Terminal {
+	t.Print(t.sequence("SetOverline", SetOverline(on), on))
 	return t
 }
 
@@ -115,7 +160,7 @@ func (t *Terminal) SetUnderline(on bool) *Terminal {
 //
 // b==h-2 means one last row will be fixed during scrolling.
 func (t *Terminal) SetScrollRegion(h, b int) *Terminal {
-	t.Print(SetScrollRegion(h, b))
+	t.Print(t.sequence("SetScrollRegion", SetScrollRegion(h, b), h, b))
 	return t
 }
 
@@ -124,7 +169,7 @@ func (t *Terminal) SetScrollRegion(h, b int) *Terminal {
 // the gap with the current background color. The area affected can be controlled
 // using SetScrollRegion.
 func (t *Terminal) ScrollBy(yDiff int) *Terminal {
-	t.Print(ScrollBy(yDiff))
+	t.Print(t.sequence("ScrollBy", ScrollBy(yDiff), yDiff))
 	return t
 }
 
@@ -132,7 +177,7 @@ func (t *Terminal) ScrollBy(yDiff int) *Terminal {
 // line without moving the cursor. Clearing happens with the current background
 // color.
 func (t *Terminal) EraseRestOfLine() *Terminal {
-	t.Print(EraseRestOfLine())
+	t.Print(t.sequence("EraseRestOfLine", EraseRestOfLine()))
 	return t
 }
 
@@ -140,7 +185,7 @@ func (t *Terminal) EraseRestOfLine() *Terminal {
 // and down until the bottom right of the screen without moving the cursor.
 // Clearing happens with the current background color.
 func (t *Terminal) EraseRestOfScreen() *Terminal {
-	t.Print(EraseRestOfScreen())
+	t.Print(t.sequence("EraseRestOfScreen", EraseRestOfScreen()))
 	return t
 }
 
@@ -148,7 +193,7 @@ func (t *Terminal) EraseRestOfScreen() *Terminal {
 // including current cursor position without moving the cursor. Clearing happens
 // with the current background color.
 func (t *Terminal) EraseFrontOfLine() *Terminal {
-	t.Print(EraseFrontOfLine())
+	t.Print(t.sequence("EraseFrontOfLine", EraseFrontOfLine()))
 	return t
 }
 
@@ -156,21 +201,21 @@ func (t *Terminal) EraseFrontOfLine() *Terminal {
 // current cursor position without moving the cursor. Clearing happens with the
 // current background color.
 func (t *Terminal) EraseFrontOfScreen() *Terminal {
-	t.Print(EraseFrontOfScreen())
+	t.Print(t.sequence("EraseFrontOfScreen", EraseFrontOfScreen()))
 	return t
 }
 
 // EraseLine erases the whole current line without moving the cursor.
 // Clearing happens with the current background color.
 func (t *Terminal) EraseLine() *Terminal {
-	t.Print(EraseLine())
+	t.Print(t.sequence("EraseLine", EraseLine()))
 	return t
 }
 
 // EraseScreen clears the while screen without moving the cursor.
 // Clearing happens with the current background color.
 func (t *Terminal) EraseScreen() *Terminal {
-	t.Print(EraseScreen())
+	t.Print(t.sequence("EraseScreen", EraseScreen()))
 	return t
 }
 
@@ -178,7 +223,7 @@ func (t *Terminal) EraseScreen() *Terminal {
 // to return to the original buffer using EndAlternativeBuffer. This allows for
 // isolated modifications.
 func (t *Terminal) StartAlternativeBuffer() *Terminal {
-	t.Print(StartAlternativeBuffer())
+	t.Print(t.sequence("StartAlternativeBuffer", StartAlternativeBuffer()))
 	return t
 }
 
@@ -188,9 +233,9 @@ func (t *Terminal) StartAlternativeBuffer() *Terminal {
 // unnecessary. If the program interrupts in the middle, it seems necessary
 // to implicitly call EndAlternativeBuffer, otherwise the console will print
 // out the prompt with the alternative buffer settings, and at least in case of cmd.exe
-// continues typing with these settings.
+// continues typing with these settings. Terminal.Session takes care of that.
 func (t *Terminal) EndAlternativeBuffer() *Terminal {
-	t.Print(EndAlternativeBuffer())
+	t.Print(t.sequence("EndAlternativeBuffer", EndAlternativeBuffer()))
 	return t
 }
 
@@ -198,14 +243,14 @@ func (t *Terminal) EndAlternativeBuffer() *Terminal {
 // Spaces will be added to fill the gap, and anything going beyond the borders of
 // viewport will be trimmed.
 func (t *Terminal) ShiftRight(amount int) *Terminal {
-	t.Print(ShiftRight(amount))
+	t.Print(t.sequence("ShiftRight", ShiftRight(amount), amount))
 	return t
 }
 
 // EraseShiftLeft deletes amount of characters at the current cursor position,
 // shifting in space character from the right edge of the viewport.
 func (t *Terminal) EraseShiftLeft(amount int) *Terminal {
-	t.Print(EraseShiftLeft(amount))
+	t.Print(t.sequence("EraseShiftLeft", EraseShiftLeft(amount), amount))
 	return t
 }
 
@@ -213,21 +258,21 @@ func (t *Terminal) EraseShiftLeft(amount int) *Terminal {
 // the cursor by overwriting characters with a space character and not wrapping
 // after reaching the right screen border.
 func (t *Terminal) Erase(amount int) *Terminal {
-	t.Print(Erase(amount))
+	t.Print(t.sequence("Erase", Erase(amount), amount))
 	return t
 }
 
 // ShiftDown shifts the current line down by amount, adding empty line(s) to fill
 // the gap. Scrolling margins set with SetScrollRegion will be respected.
 func (t *Terminal) ShiftDown(amount int) *Terminal {
-	t.Print(ShiftDown(amount))
+	t.Print(t.sequence("ShiftDown", ShiftDown(amount), amount))
 	return t
 }
 
 // DeleteLines deletes amount of lines from the buffer, starting with the row the
 // cursor is on. Scrolling margins set with SetScrollRegion will be respected.
 func (t *Terminal) DeleteLines(amount int) *Terminal {
-	t.Print(DeleteLines(amount))
+	t.Print(t.sequence("DeleteLines", DeleteLines(amount), amount))
 	return t
 }
 
@@ -235,22 +280,120 @@ func (t *Terminal) DeleteLines(amount int) *Terminal {
 // This actually seems to swap the meaning of fg and bg and can be stacked.
 // Output CancelSwap to return to normal.
 func (t *Terminal) Swap() *Terminal {
-	t.Print(Swap())
+	t.Print(t.sequence("Swap", Swap()))
 	return t
 }
 
 // CancelSwap returns foreground/background to normal after any proceeding Swap.
 func (t *Terminal) CancelSwap() *Terminal {
-	t.Print(CancelSwap())
+	t.Print(t.sequence("CancelSwap", CancelSwap()))
 	return t
 }
 
+// FgRGB sets foreground color to RGB value. Components are limited to 0–255.
+// Terminals not supporting true colors get the nearest color, see
+// ColorProfile.
 func (t *Terminal) FgRGB(r, g, b int) *Terminal {
-	t.Print(FgRGB(r, g, b))
+	t.Print(t.sequence("FgRGB", FgRGB(r, g, b), r, g, b))
 	return t
 }
 
+// BgRGB sets background color to RGB value. Components are limited to 0–255.
+// Terminals not supporting true colors get the nearest color, see
+// ColorProfile.
 func (t *Terminal) BgRGB(r, g, b int) *Terminal {
-	t.Print(BgRGB(r, g, b))
+	t.Print(t.sequence("BgRGB", BgRGB(r, g, b), r, g, b))
+	return t
+}
+
+// Fg256 sets foreground color to n-th color (0–255) of the 256-color palette.
+// See Palette256 for colors and RGBTo256 to pick one.
+func (t *Terminal) Fg256(n int) *Terminal {
+	t.Print(t.sequence("Fg256", Fg256(n), n))
+	return t
+}
+
+// Bg256 sets background color to n-th color (0–255) of the 256-color palette.
+// See Palette256 for colors and RGBTo256 to pick one.
+func (t *Terminal) Bg256(n int) *Terminal {
+	t.Print(t.sequence("Bg256", Bg256(n), n))
+	return t
+}
+
+// DefaultFg restores foreground color to the default, without affecting other
+// attributes unlike Reset.
+func (t *Terminal) DefaultFg() *Terminal {
+	t.Print(t.sequence("DefaultFg", DefaultFg()))
+	return t
+}
+
+// DefaultBg restores background color to the default, without affecting other
+// attributes unlike Reset.
+func (t *Terminal) DefaultBg() *Terminal {
+	t.Print(t.sequence("DefaultBg", DefaultBg()))
+	return t
+}
+
+// UnderlineRGB sets color of the underline to RGB value. Components are limited
+// to 0–255. By default, underline has the color of the text.
+func (t *Terminal) UnderlineRGB(r, g, b int) *Terminal {
+	t.Print(t.sequence("UnderlineRGB", UnderlineRGB(r, g, b), r, g, b))
+	return t
+}
+
+// Underline256 sets color of the underline to n-th color (0–255) of the
+// 256-color palette.
+func (t *Terminal) Underline256(n int) *Terminal {
+	t.Print(t.sequence("Underline256", Underline256(n), n))
+	return t
+}
+
+// DefaultUnderlineColor restores underline color to the color of the text.
+func (t *Terminal) DefaultUnderlineColor() *Terminal {
+	t.Print(t.sequence("DefaultUnderlineColor", DefaultUnderlineColor()))
+	return t
+}
+
+// SetMouseButtons turns on / off reporting of mouse button presses and
+// releases, including the wheel, as MouseEvent. Combine it with SetSGRMouse
+// for terminals wider than 223 columns.
+func (t *Terminal) SetMouseButtons(on bool) *Terminal {
+	t.Print(t.sequence("SetMouseButtons", SetMouseButtons(on), on))
+	return t
+}
+
+// SetMouseDrag turns on / off reporting of mouse motion while a button is held,
+// in addition to what SetMouseButtons reports.
+func (t *Terminal) SetMouseDrag(on bool) *Terminal {
+	t.Print(t.sequence("SetMouseDrag", SetMouseDrag(on), on))
+	return t
+}
+
+// SetMouseMotion turns on / off reporting of all mouse motion, even without
+// buttons held, in addition to what SetMouseButtons reports.
+func (t *Terminal) SetMouseMotion(on bool) *Terminal {
+	t.Print(t.sequence("SetMouseMotion", SetMouseMotion(on), on))
+	return t
+}
+
+// SetSGRMouse turns on / off the extended format of mouse reports, which has no
+// limit on coordinates and tells which button is released. It doesn't enable
+// reporting by itself.
+func (t *Terminal) SetSGRMouse(on bool) *Terminal {
+	t.Print(t.sequence("SetSGRMouse", SetSGRMouse(on), on))
+	return t
+}
+
+// SetBracketedPaste turns on / off marking of pasted text, so that it is read
+// as a single PasteEvent instead of separate keys.
+func (t *Terminal) SetBracketedPaste(on bool) *Terminal {
+	t.Print(t.sequence("SetBracketedPaste", SetBracketedPaste(on), on))
+	return t
+}
+
+// SetFocusReporting turns on / off reporting of the terminal window gaining and
+// losing focus as FocusEvent.
+func (t *Terminal) SetFocusReporting(on bool) *Terminal {
+	t.Print(t.sequence("SetFocusReporting", SetFocusReporting(on), on))
 	return t
 }
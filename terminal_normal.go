@@ -14,7 +14,7 @@ func IsTerminal(fd int) bool {
 
 // ReadPassword reads a line of input from a terminal without local echo. This
 // is commonly used for inputting passwords and other sensitive data. The slice
-// returned does not include the \n.
+// returned does not include the \n. See Terminal.ReadSecret for masking input.
 func ReadPassword(fd int) ([]byte, error) {
 	return term.ReadPassword(fd)
 }
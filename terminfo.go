@@ -0,0 +1,165 @@
+package terminal
+
+import "github.com/zzwx/terminal/terminfo"
+
+// SetTerminfo makes the terminal emit sequences from the terminfo entry ti
+// instead of the built-in xterm ones wherever the entry has a matching
+// capability, such as cup for MoveToXY or smcup for StartAlternativeBuffer.
+// Sequences the entry lacks still fall back to the built-in ones. nil returns to
+// using only the built-in sequences.
+func (t *Terminal) SetTerminfo(ti *terminfo.Terminfo) {
+	t.ti = ti
+}
+
+// Terminfo returns the entry set with SetTerminfo or LoadTerminfo, or nil.
+func (t *Terminal) Terminfo() *terminfo.Terminfo {
+	return t.ti
+}
+
+// LoadTerminfo loads the terminfo entry for $TERM and passes it to SetTerminfo.
+// This is useful inside screen, tmux or the Linux console, where some xterm
+// sequences are not understood.
+func (t *Terminal) LoadTerminfo() error {
+	ti, err := terminfo.Load("")
+	if err != nil {
+		return err
+	}
+	t.SetTerminfo(ti)
+	return nil
+}
+
+// sequence returns the terminfo replacement for builtin, which is what function
+// name from terminal_funcs.go returned for args, or builtin itself if there is
+// no terminfo entry set or it lacks the capability. The mode it changes is
+// recorded for Restore.
+func (t *Terminal) sequence(name string, builtin string, args ...interface{}) string {
+	t.record(name, args)
+	ti := t.ti
+	if ti == nil {
+		return builtin
+	}
+	if f, ok := terminfoSequences[name]; ok {
+		if s, ok := f(ti, args); ok {
+			return s
+		}
+	}
+	return builtin
+}
+
+// terminfoSequences maps functions of terminal_funcs.go to terminfo
+// capabilities. Functions report false when the capability is missing.
+var terminfoSequences = map[string]func(ti *terminfo.Terminfo, args []interface{}) (string, bool){
+	"MoveByX": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		return relative(ti, args[0].(int), "cub", "cuf")
+	},
+	"MoveByY": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		return relative(ti, args[0].(int), "cuu", "cud")
+	},
+	"MoveUpScroll": capability("ri"),
+	"MoveToXY": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		x, y := args[0].(int), args[1].(int)
+		if x < 0 {
+			x = 0
+		}
+		if y < 0 {
+			y = 0
+		}
+		return ti.Parm("cup", y, x)
+	},
+	"MoveTopLeft": capability("home"),
+	"MoveToX": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		if args[0].(int) < 0 {
+			return "", true
+		}
+		return ti.Parm("hpa", args[0].(int))
+	},
+	"MoveToY": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		if args[0].(int) < 0 {
+			return "", true
+		}
+		return ti.Parm("vpa", args[0].(int))
+	},
+	"SavePos":          capability("sc"),
+	"RestorePos":       capability("rc"),
+	"SetCursorVisible": toggle("cnorm", "civis"),
+	"SetBright":        toggle("bold", ""),
+	"SetUnderline":     toggle("smul", "rmul"),
+	"SetDim":           toggle("dim", ""),
+	"SetItalic":        toggle("sitm", "ritm"),
+	"SetTextBlink":     toggle("blink", ""),
+	"SetHidden":        toggle("invis", ""),
+	"SetScrollRegion": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		return ti.Parm("csr", args[0].(int), args[1].(int))
+	},
+	"ScrollBy": func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		// Single line ind and ri only scroll at the margins, so they don't fit
+		yDiff := args[0].(int)
+		switch {
+		case yDiff < 0:
+			return ti.Parm("indn", -yDiff)
+		case yDiff > 0:
+			return ti.Parm("rin", yDiff)
+		}
+		return "", true
+	},
+	"EraseRestOfLine":        capability("el"),
+	"EraseRestOfScreen":      capability("ed"),
+	"EraseFrontOfLine":       capability("el1"),
+	"StartAlternativeBuffer": capability("smcup"),
+	"EndAlternativeBuffer":   capability("rmcup"),
+	"ShiftRight":             count("ich"),
+	"EraseShiftLeft":         count("dch"),
+	"Erase":                  count("ech"),
+	"ShiftDown":              count("il"),
+	"DeleteLines":            count("dl"),
+	"Swap":                   capability("rev"),
+	"Reset":                  capability("sgr0"),
+}
+
+// capability returns a mapping to capability name without parameters.
+func capability(name string) func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+	return func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		return ti.Parm(name)
+	}
+}
+
+// toggle returns a mapping of a function with a bool argument to capabilities
+// on and off. Empty name means there is no capability for that state.
+func toggle(on, off string) func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+	return func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		name := off
+		if args[0].(bool) {
+			name = on
+		}
+		if name == "" {
+			return "", false
+		}
+		return ti.Parm(name)
+	}
+}
+
+// count returns a mapping of a function with a positive amount argument to the
+// parameterized capability name, such as "ich".
+func count(name string) func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+	return func(ti *terminfo.Terminfo, args []interface{}) (string, bool) {
+		n := args[0].(int)
+		if n <= 0 {
+			return "", true
+		}
+		return ti.Parm(name, n)
+	}
+}
+
+// relative maps a signed difference to parameterized capabilities for the
+// negative and positive direction. Single step versions, such as "cud1", are
+// not used since they are often plain control characters with side effects,
+// like "\n" scrolling at the bottom of the screen.
+func relative(ti *terminfo.Terminfo, diff int, negative, positive string) (string, bool) {
+	switch {
+	case diff < 0:
+		return ti.Parm(negative, -diff)
+	case diff > 0:
+		return ti.Parm(positive, diff)
+	}
+	return "", true
+}
@@ -0,0 +1,312 @@
+package terminfo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expand substitutes params into capability s the way tparm(3) does it,
+// evaluating the %-encoded stack language of terminfo. Padding specifications
+// like "$<5>" are removed, since terminals emulated in software don't need
+// them. Static variables (%P[A-Z]) don't survive between calls.
+func Expand(s string, params ...int) string {
+	var p [9]int
+	copy(p[:], params)
+	e := expander{params: p}
+	return e.run(s)
+}
+
+// value is an entry of the expansion stack, which holds both numbers and
+// strings.
+type value struct {
+	n     int
+	s     string
+	isStr bool
+}
+
+type expander struct {
+	params [9]int
+	stack  []value
+	vars   [52]int
+}
+
+func (e *expander) push(v value) {
+	e.stack = append(e.stack, v)
+}
+
+func (e *expander) pushInt(n int) {
+	e.push(value{n: n})
+}
+
+func (e *expander) pushBool(b bool) {
+	if b {
+		e.pushInt(1)
+	} else {
+		e.pushInt(0)
+	}
+}
+
+// pop returns the top of the stack, or 0 if the stack is empty.
+func (e *expander) pop() value {
+	if len(e.stack) == 0 {
+		return value{}
+	}
+	v := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return v
+}
+
+func (e *expander) popInt() int {
+	v := e.pop()
+	if v.isStr {
+		n, _ := strconv.Atoi(v.s)
+		return n
+	}
+	return v.n
+}
+
+func (e *expander) popStr() string {
+	v := e.pop()
+	if v.isStr {
+		return v.s
+	}
+	return strconv.Itoa(v.n)
+}
+
+func (e *expander) run(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '$' && i+1 < len(s) && s[i+1] == '<' {
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				i += end
+				continue
+			}
+		}
+		if c != '%' || i+1 >= len(s) {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		c = s[i]
+		switch c {
+		case '%':
+			out.WriteByte('%')
+		case 'c':
+			out.WriteByte(byte(e.popInt()))
+		case 's':
+			out.WriteString(e.popStr())
+		case 'd', 'o', 'x', 'X', ':', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.', '#', ' ':
+			end := i
+			for end < len(s) && strings.IndexByte("doxXs", s[end]) < 0 {
+				end++
+			}
+			if end >= len(s) {
+				return out.String()
+			}
+			out.WriteString(e.format(s[i:end], s[end]))
+			i = end
+		case 'p':
+			if i+1 < len(s) && s[i+1] >= '1' && s[i+1] <= '9' {
+				i++
+				e.pushInt(e.params[s[i]-'1'])
+			}
+		case 'P', 'g':
+			if i+1 >= len(s) {
+				return out.String()
+			}
+			i++
+			var idx int
+			switch v := s[i]; {
+			case v >= 'a' && v <= 'z':
+				idx = int(v - 'a')
+			case v >= 'A' && v <= 'Z':
+				idx = 26 + int(v-'A')
+			default:
+				continue
+			}
+			if c == 'P' {
+				e.vars[idx] = e.popInt()
+			} else {
+				e.pushInt(e.vars[idx])
+			}
+		case '\'':
+			if i+2 < len(s) && s[i+2] == '\'' {
+				e.pushInt(int(s[i+1]))
+				i += 2
+			}
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return out.String()
+			}
+			n, _ := strconv.Atoi(s[i+1 : i+end])
+			e.pushInt(n)
+			i += end
+		case 'l':
+			e.pushInt(len(e.popStr()))
+		case '+', '-', '*', '/', 'm', '&', '|', '^', '=', '>', '<', 'A', 'O':
+			b, a := e.popInt(), e.popInt()
+			switch c {
+			case '+':
+				e.pushInt(a + b)
+			case '-':
+				e.pushInt(a - b)
+			case '*':
+				e.pushInt(a * b)
+			case '/':
+				if b == 0 {
+					e.pushInt(0)
+				} else {
+					e.pushInt(a / b)
+				}
+			case 'm':
+				if b == 0 {
+					e.pushInt(0)
+				} else {
+					e.pushInt(a % b)
+				}
+			case '&':
+				e.pushInt(a & b)
+			case '|':
+				e.pushInt(a | b)
+			case '^':
+				e.pushInt(a ^ b)
+			case '=':
+				e.pushBool(a == b)
+			case '>':
+				e.pushBool(a > b)
+			case '<':
+				e.pushBool(a < b)
+			case 'A':
+				e.pushBool(a != 0 && b != 0)
+			case 'O':
+				e.pushBool(a != 0 || b != 0)
+			}
+		case '!':
+			e.pushBool(e.popInt() == 0)
+		case '~':
+			e.pushInt(^e.popInt())
+		case 'i':
+			e.params[0]++
+			e.params[1]++
+		case '?', ';':
+			// Conditions are evaluated at %t
+		case 't':
+			if e.popInt() == 0 {
+				i = skip(s, i+1, true)
+			}
+		case 'e':
+			// Reached the end of a taken branch
+			i = skip(s, i+1, false)
+		}
+	}
+	return out.String()
+}
+
+// skip returns the position before the text to continue the expansion from,
+// skipping a branch of the conditional at i. When toElse is true, it stops
+// after the matching %e, otherwise only after the matching %;.
+func skip(s string, i int, toElse bool) int {
+	depth := 0
+	for ; i < len(s)-1; i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		switch s[i] {
+		case '?':
+			depth++
+		case ';':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case 'e':
+			if depth == 0 && toElse {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// format formats the popped value using printf-like flags, width and precision
+// of %[[:]flags][width[.precision]][doxXs].
+func (e *expander) format(spec string, verb byte) string {
+	spec = strings.TrimPrefix(spec, ":")
+	var left, plus, space, alt, zero bool
+	for len(spec) > 0 {
+		switch spec[0] {
+		case '-':
+			left = true
+		case '+':
+			plus = true
+		case ' ':
+			space = true
+		case '#':
+			alt = true
+		case '0':
+			zero = true
+		default:
+			goto width
+		}
+		spec = spec[1:]
+	}
+width:
+	precision := -1
+	widthStr := spec
+	if dot := strings.IndexByte(spec, '.'); dot >= 0 {
+		widthStr = spec[:dot]
+		precision, _ = strconv.Atoi(spec[dot+1:])
+	}
+	width, _ := strconv.Atoi(widthStr)
+
+	var body, sign string
+	if verb == 's' {
+		body = e.popStr()
+		if precision >= 0 && precision < len(body) {
+			body = body[:precision]
+		}
+	} else {
+		n := e.popInt()
+		if n < 0 && verb == 'd' {
+			sign = "-"
+			n = -n
+		} else if verb == 'd' && plus {
+			sign = "+"
+		} else if verb == 'd' && space {
+			sign = " "
+		}
+		switch verb {
+		case 'd':
+			body = strconv.Itoa(n)
+		case 'o':
+			body = strconv.FormatUint(uint64(uint32(n)), 8)
+			if alt && body[0] != '0' {
+				body = "0" + body
+			}
+		case 'x', 'X':
+			body = strconv.FormatUint(uint64(uint32(n)), 16)
+			if verb == 'X' {
+				body = strings.ToUpper(body)
+			}
+		}
+		for len(body) < precision {
+			body = "0" + body
+		}
+		if alt && (verb == 'x' || verb == 'X') && n != 0 {
+			sign += "0" + string(verb)
+		}
+	}
+	pad := width - len(sign) - len(body)
+	switch {
+	case pad <= 0:
+		return sign + body
+	case left:
+		return sign + body + strings.Repeat(" ", pad)
+	case zero && verb != 's' && precision < 0:
+		return sign + strings.Repeat("0", pad) + body
+	}
+	return strings.Repeat(" ", pad) + sign + body
+}
@@ -0,0 +1,290 @@
+// Package terminfo reads compiled terminfo database entries, which describe
+// sequences understood by terminals that don't follow xterm conventions, such
+// as the Linux console, screen or old tmux configurations.
+package terminfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned by Load when there is no entry for the terminal in
+// any of the terminfo directories.
+var ErrNotFound = errors.New("terminfo entry not found")
+
+const (
+	magicLegacy   = 0432  // Numbers are 16-bit
+	magicExtended = 01036 // Numbers are 32-bit
+)
+
+// Terminfo is a terminal description read from the terminfo database.
+// Capabilities are accessed by their short names, such as "cup" or "smcup",
+// including the user-defined extended ones, such as "Smulx" or "RGB".
+type Terminfo struct {
+	// Names are the terminal names, the first one being the primary name and the
+	// last one a long description.
+	Names   []string
+	Bools   map[string]bool
+	Numbers map[string]int
+	Strings map[string]string
+}
+
+// Load finds and reads the terminfo entry for the terminal name, such as
+// "xterm-256color" or "linux". An empty name means $TERM. Directories are
+// searched in the same order as ncurses does it: $TERMINFO, ~/.terminfo,
+// $TERMINFO_DIRS and then the system directories.
+func Load(name string) (*Terminfo, error) {
+	if name == "" {
+		name = os.Getenv("TERM")
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name[0] == '.' {
+		return nil, fmt.Errorf("invalid terminal name %q", name)
+	}
+	for _, dir := range Dirs() {
+		for _, sub := range []string{name[:1], strconv.FormatInt(int64(name[0]), 16)} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, name))
+			if err != nil {
+				continue
+			}
+			return Parse(data)
+		}
+	}
+	return nil, fmt.Errorf("%w for %q", ErrNotFound, name)
+}
+
+// Dirs returns directories where Load looks for terminfo entries.
+func Dirs() []string {
+	var dirs []string
+	if d := os.Getenv("TERMINFO"); d != "" {
+		dirs = append(dirs, d)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	system := []string{"/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo", "/usr/share/lib/terminfo"}
+	if d := os.Getenv("TERMINFO_DIRS"); d != "" {
+		for _, d := range strings.Split(d, string(os.PathListSeparator)) {
+			if d == "" {
+				// An empty entry means the system default
+				dirs = append(dirs, system...)
+			} else {
+				dirs = append(dirs, d)
+			}
+		}
+	}
+	return append(dirs, system...)
+}
+
+// Parse parses a compiled terminfo entry in either legacy or extended number
+// format, including the extended capabilities section.
+func Parse(data []byte) (*Terminfo, error) {
+	r := reader{data: data}
+	magic := r.short()
+	var numberSize int
+	switch magic {
+	case magicLegacy:
+		numberSize = 2
+	case magicExtended:
+		numberSize = 4
+	default:
+		return nil, fmt.Errorf("invalid terminfo magic number %#o", magic)
+	}
+	namesSize, boolCount, numberCount, stringCount, tableSize := r.short(), r.short(), r.short(), r.short(), r.short()
+	if r.err != nil || namesSize < 0 || boolCount < 0 || numberCount < 0 || stringCount < 0 || tableSize < 0 {
+		return nil, errors.New("invalid terminfo header")
+	}
+	ti := &Terminfo{
+		Bools:   make(map[string]bool),
+		Numbers: make(map[string]int),
+		Strings: make(map[string]string),
+	}
+	ti.Names = strings.Split(strings.TrimRight(string(r.bytes(namesSize)), "\x00"), "|")
+	for i, b := range r.bytes(boolCount) {
+		if b == 1 && i < len(boolNames) {
+			ti.Bools[boolNames[i]] = true
+		}
+	}
+	r.align()
+	for i := 0; i < numberCount; i++ {
+		v := r.number(numberSize)
+		if v >= 0 && i < len(numberNames) {
+			ti.Numbers[numberNames[i]] = v
+		}
+	}
+	offsets := make([]int, stringCount)
+	for i := range offsets {
+		offsets[i] = r.short()
+	}
+	table := r.bytes(tableSize)
+	for i, off := range offsets {
+		if off >= 0 && i < len(stringNames) {
+			if s, ok := cString(table, off); ok {
+				ti.Strings[stringNames[i]] = s
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.pos < len(data) {
+		r.align()
+		if err := ti.parseExtended(&r, numberSize); err != nil {
+			return nil, err
+		}
+	}
+	return ti, nil
+}
+
+// parseExtended parses the section of user-defined capabilities following the
+// standard ones. Unlike the standard capabilities, names are stored in the
+// file, after the string values.
+func (ti *Terminfo) parseExtended(r *reader, numberSize int) error {
+	boolCount, numberCount, stringCount, _, tableSize := r.short(), r.short(), r.short(), r.short(), r.short()
+	if r.err != nil || boolCount < 0 || numberCount < 0 || stringCount < 0 || tableSize < 0 {
+		return errors.New("invalid terminfo extended header")
+	}
+	bools := r.bytes(boolCount)
+	r.align()
+	numbers := make([]int, numberCount)
+	for i := range numbers {
+		numbers[i] = r.number(numberSize)
+	}
+	values := make([]int, stringCount)
+	for i := range values {
+		values[i] = r.short()
+	}
+	names := make([]int, boolCount+numberCount+stringCount)
+	for i := range names {
+		names[i] = r.short()
+	}
+	table := r.bytes(tableSize)
+	if r.err != nil {
+		return r.err
+	}
+	// Names are stored after the last string value.
+	namesStart := 0
+	for _, off := range values {
+		if off < 0 {
+			continue
+		}
+		if s, ok := cString(table, off); ok && off+len(s)+1 > namesStart {
+			namesStart = off + len(s) + 1
+		}
+	}
+	name := func(i int) (string, bool) {
+		if names[i] < 0 || namesStart+names[i] >= len(table) {
+			return "", false
+		}
+		return cString(table, namesStart+names[i])
+	}
+	for i, b := range bools {
+		if n, ok := name(i); ok && b == 1 {
+			ti.Bools[n] = true
+		}
+	}
+	for i, v := range numbers {
+		if n, ok := name(boolCount + i); ok && v >= 0 {
+			ti.Numbers[n] = v
+		}
+	}
+	for i, off := range values {
+		if n, ok := name(boolCount + numberCount + i); ok && off >= 0 {
+			if s, ok := cString(table, off); ok {
+				ti.Strings[n] = s
+			}
+		}
+	}
+	return nil
+}
+
+// Bool reports whether boolean capability name is present.
+func (ti *Terminfo) Bool(name string) bool {
+	return ti.Bools[name]
+}
+
+// Number returns numeric capability name.
+func (ti *Terminfo) Number(name string) (int, bool) {
+	v, ok := ti.Numbers[name]
+	return v, ok
+}
+
+// String returns string capability name as is, with parameters and padding left
+// unexpanded.
+func (ti *Terminfo) String(name string) (string, bool) {
+	s, ok := ti.Strings[name]
+	return s, ok
+}
+
+// Parm returns string capability name with parameters expanded by Expand.
+func (ti *Terminfo) Parm(name string, params ...int) (string, bool) {
+	s, ok := ti.Strings[name]
+	if !ok {
+		return "", false
+	}
+	return Expand(s, params...), true
+}
+
+// reader reads little-endian values from a compiled entry, remembering the
+// first error.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errTruncated = errors.New("truncated terminfo entry")
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// short reads a signed 16-bit value, where -1 means absent and -2 cancelled
+// capability.
+func (r *reader) short() int {
+	b := r.bytes(2)
+	if b == nil {
+		return -1
+	}
+	return int(int16(binary.LittleEndian.Uint16(b)))
+}
+
+func (r *reader) number(size int) int {
+	if size == 2 {
+		return r.short()
+	}
+	b := r.bytes(4)
+	if b == nil {
+		return -1
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+// align skips a byte to an even position.
+func (r *reader) align() {
+	if r.pos%2 == 1 {
+		r.bytes(1)
+	}
+}
+
+// cString returns the NUL terminated string at offset off of table.
+func cString(table []byte, off int) (string, bool) {
+	if off < 0 || off >= len(table) {
+		return "", false
+	}
+	end := off
+	for end < len(table) && table[end] != 0 {
+		end++
+	}
+	return string(table[off:end]), true
+}
@@ -0,0 +1,90 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestQueryBackgroundColor(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  terminal.Color
+	}{
+		{"rgb:ffff/8080/0000\x1b\\", terminal.RGB(255, 128, 0)},
+		{"rgb:ff/80/00\x07", terminal.RGB(255, 128, 0)},
+		{"rgb:ffff/80/000\x1b\\", terminal.RGB(255, 128, 0)},
+		{"rgb:fff/800/000\x07", terminal.RGB(255, 128, 0)},
+		{"rgb:f/8/0\x07", terminal.RGB(255, 136, 0)},
+		{"rgba:ffff/ffff/ffff/ffff\x1b\\", terminal.RGB(255, 255, 255)},
+		{"#1e1e2e\x07", terminal.RGB(0x1e, 0x1e, 0x2e)},
+	}
+	for _, test := range tests {
+		term, _ := newPtyTerminal(t, map[string]string{"\x1b]11;?": "\x1b]11;" + test.reply + "\x1b[?62c"})
+		c, err := term.QueryBackgroundColor(time.Second)
+		if err != nil || c != test.want {
+			t.Errorf("reply %q: QueryBackgroundColor() = %v, %v, want %v", test.reply, c, err, test.want)
+		}
+	}
+
+	term, _ := newPtyTerminal(t, map[string]string{"\x1b]10;?": "\x1b]10;rgb:0000/0000/ffff\x1b\\\x1b[?62c"})
+	if c, err := term.QueryForegroundColor(time.Second); err != nil || c != terminal.RGB(0, 0, 255) {
+		t.Errorf("QueryForegroundColor() = %v, %v, want blue", c, err)
+	}
+	for _, reply := range []string{"rgb:ff/ff\x07", "rgb:fffff/0/0\x07", "rgb:xx/00/00\x07"} {
+		term, _ := newPtyTerminal(t, map[string]string{"\x1b]11;?": "\x1b]11;" + reply + "\x1b[?62c"})
+		if c, err := term.QueryBackgroundColor(time.Second); err == nil {
+			t.Errorf("reply %q: QueryBackgroundColor() = %v, want an error", reply, c)
+		}
+	}
+	term, _ = newPtyTerminal(t, map[string]string{"\x1b]11;?": "\x1b[?62c"})
+	if _, err := term.QueryBackgroundColor(time.Second); !errors.Is(err, terminal.ErrNoReply) {
+		t.Errorf("QueryBackgroundColor() without a reply = %v, want ErrNoReply", err)
+	}
+}
+
+func TestHasDarkBackground(t *testing.T) {
+	saved, ok := os.LookupEnv("COLORFGBG")
+	defer func() {
+		if ok {
+			_ = os.Setenv("COLORFGBG", saved)
+		} else {
+			_ = os.Unsetenv("COLORFGBG")
+		}
+	}()
+	_ = os.Setenv("COLORFGBG", "0;15")
+	for reply, want := range map[string]bool{
+		"\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?62c": true,
+		"\x1b]11;rgb:2828/2c2c/3434\x1b\\\x1b[?62c": true,
+		"\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[?62c": false,
+		"\x1b]11;rgb:fdfd/f6f6/e3e3\x1b\\\x1b[?62c": false,
+	} {
+		term, _ := newPtyTerminal(t, map[string]string{"\x1b]11;?": reply})
+		if got := term.HasDarkBackground(); got != want {
+			t.Errorf("reply %q: HasDarkBackground() = %v, want %v", reply, got, want)
+		}
+	}
+
+	// Without a reply, COLORFGBG is used
+	for colorFgBg, want := range map[string]bool{
+		"15;0":         true,
+		"0;15":         false,
+		"0;7":          false,
+		"15;default;8": true,
+		"0;default;11": false,
+		"default":      true,
+		"":             true,
+	} {
+		_ = os.Setenv("COLORFGBG", colorFgBg)
+		term, _ := newPtyTerminal(t, map[string]string{"\x1b]11;?": "\x1b[?62c"})
+		if got := term.HasDarkBackground(); got != want {
+			t.Errorf("COLORFGBG=%q: HasDarkBackground() = %v, want %v", colorFgBg, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestProbeCapabilities(t *testing.T) {
+	saved, ok := os.LookupEnv("COLORTERM")
+	defer func() {
+		if ok {
+			_ = os.Setenv("COLORTERM", saved)
+		} else {
+			_ = os.Unsetenv("COLORTERM")
+		}
+	}()
+	_ = os.Unsetenv("COLORTERM")
+	tests := []struct {
+		reply string
+		want  terminal.Capabilities
+	}{
+		{
+			"\x1bP>|XTerm(370)\x1b\\\x1b[>41;370;0c\x1b[?64;1;2;4;6;9;15;18;21;22c",
+			terminal.Capabilities{Name: "XTerm", Version: "370", ConformanceLevel: 64, Features: []int{1, 2, 4, 6, 9, 15, 18, 21, 22}, Type: 41, Firmware: 370, Sixel: true},
+		},
+		{
+			"\x1bP>|tmux 3.3a\x1b\\\x1b[>84;0;0c\x1b[?1;2c",
+			terminal.Capabilities{Name: "tmux", Version: "3.3a", ConformanceLevel: 1, Features: []int{2}, Type: 84},
+		},
+		{
+			"\x1bP>|kitty(0.26.5)\x1b\\\x1b[>1;4000;29c\x1b[?62;c",
+			terminal.Capabilities{Name: "kitty", Version: "0.26.5", ConformanceLevel: 62, Features: []int{0}, Type: 1, Firmware: 4000, TrueColor: true},
+		},
+		{
+			"\x1b[>65;6800;1c\x1b[?65;1;9c",
+			terminal.Capabilities{ConformanceLevel: 65, Features: []int{1, 9}, Type: 65, Firmware: 6800, TrueColor: true},
+		},
+		{
+			"\x1b[?6c",
+			terminal.Capabilities{ConformanceLevel: 6, Features: []int{}},
+		},
+	}
+	for _, test := range tests {
+		term, remote := newPtyTerminal(t, map[string]string{"\x1b[>0q": test.reply})
+		c, err := term.ProbeCapabilities(time.Second)
+		if err != nil || !reflect.DeepEqual(c, test.want) {
+			t.Errorf("reply %q: ProbeCapabilities() = %+v, %v, want %+v", test.reply, c, err, test.want)
+		}
+		if want := "\x1b[>0q\x1b[>c\x1b[c"; remote.String() != want {
+			t.Errorf("requested %q, want %q", remote.String(), want)
+		}
+	}
+
+	term, remote := newPtyTerminal(t, map[string]string{"\x1b[>0q": "\x1b[>41;370;0c\x1b[?62c"})
+	for i := 0; i < 2; i++ {
+		if c := term.Capabilities(); c.Type != 41 || c.ConformanceLevel != 62 {
+			t.Errorf("Capabilities() = %+v", c)
+		}
+	}
+	if n := strings.Count(remote.String(), "\x1b[>0q"); n != 1 {
+		t.Errorf("Capabilities() probed %d times, want once", n)
+	}
+
+	silent, _ := newPtyTerminal(t, nil)
+	if c, err := silent.ProbeCapabilities(50 * time.Millisecond); !errors.Is(err, terminal.ErrNoReply) || !reflect.DeepEqual(c, terminal.Capabilities{}) {
+		t.Errorf("ProbeCapabilities() without a reply = %+v, %v, want ErrNoReply", c, err)
+	}
+}
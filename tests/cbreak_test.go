@@ -0,0 +1,67 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/zzwx/terminal"
+	"golang.org/x/sys/unix"
+)
+
+func TestSessionAfterCbreak(t *testing.T) {
+	slave, _ := newPty(t, nil)
+	term := terminal.NewTerminal(slave)
+	term.SetInput(slave)
+	echo := func() bool {
+		t.Helper()
+		var termios *unix.Termios
+		err := control(slave, func(fd int) (err error) {
+			termios, err = unix.IoctlGetTermios(fd, unix.TCGETS)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return termios.Lflag&unix.ECHO != 0
+	}
+	setEcho := func(on bool) {
+		t.Helper()
+		err := control(slave, func(fd int) error {
+			termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+			if err != nil {
+				return err
+			}
+			if on {
+				termios.Lflag |= unix.ECHO
+			} else {
+				termios.Lflag &^= unix.ECHO
+			}
+			return unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	term.Session()
+	restore, err := term.EnterCbreak()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo() {
+		t.Error("echo is on in cbreak mode")
+	}
+	if err := restore(); err != nil || !echo() {
+		t.Errorf("restore() = %v, want echo back on", err)
+	}
+	// Changed by something else after cbreak mode is over
+	setEcho(false)
+	if err := term.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if echo() {
+		t.Error("Restore() restored cbreak mode which has already been restored")
+	}
+}
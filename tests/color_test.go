@@ -0,0 +1,263 @@
+package tests
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestColorProfileConvert(t *testing.T) {
+	tests := []struct {
+		profile terminal.ColorProfile
+		in      string
+		want    string
+	}{
+		{terminal.TrueColor, terminal.FgRGB(255, 0, 0) + "x", terminal.FgRGB(255, 0, 0) + "x"},
+		{terminal.ANSI256, terminal.FgRGB(255, 0, 0) + "x", terminal.CSI + "38;5;196mx"},
+		{terminal.ANSI256, terminal.BgRGB(128, 128, 128), terminal.CSI + "48;5;244m"},
+		{terminal.ANSI16, terminal.FgRGB(255, 0, 0) + "x", terminal.FgHiRed + "x"},
+		{terminal.ANSI16, terminal.BgRGB(0, 0, 0), terminal.BgBlack},
+		{terminal.ANSI16, terminal.CSI + "1;38;2;0;205;0;4m", terminal.CSI + "1;32;4m"},
+		{terminal.NoColor, terminal.CSI + "1;38;2;0;205;0;4m", terminal.CSI + "1;4m"},
+		{terminal.NoColor, terminal.FgRed + "x" + terminal.Reset, "x" + terminal.Reset},
+		{terminal.NoColor, terminal.MoveToXY(1, 2), terminal.MoveToXY(1, 2)},
+		{terminal.ANSI256, terminal.CSI + "58:2::255:0:0m", terminal.CSI + "58;5;196m"},
+		{terminal.ANSI256, terminal.CSI + "38;2;255;0\n;0mx", "\n" + terminal.CSI + "38;5;196mx"},
+		{terminal.NoColor, terminal.CSI + "3\r1mx", "\rx"},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.Convert(tt.in); got != tt.want {
+			t.Errorf("%v.Convert(%q) = %q, want %q", tt.profile, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestColorProfileSplitWrites(t *testing.T) {
+	var b bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&b)
+	term.SetColorMode(terminal.ColorAlways) // Regardless of NO_COLOR
+	term.SetColorProfile(terminal.ANSI256)
+	seq := terminal.FgRGB(255, 0, 0) + "x"
+	for i := 0; i < len(seq); i++ {
+		_, _ = term.Write([]byte{seq[i]})
+	}
+	if got, want := b.String(), terminal.CSI+"38;5;196mx"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFlushIncompleteSequence(t *testing.T) {
+	var before, after bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&before)
+	term.SetColorMode(terminal.ColorAlways)
+	term.SetColorProfile(terminal.ANSI256) // Filtered, so that sequences are held back
+	term.Print("x" + terminal.CSI + "2")
+	if got := before.String(); got != "x" {
+		t.Errorf("got %q before the sequence is complete, want %q", got, "x")
+	}
+	term.OverrideOut(&after)
+	if got, want := before.String(), "x"+terminal.CSI+"2"; got != want {
+		t.Errorf("got %q after changing the output, want %q", got, want)
+	}
+	term.Print("y" + terminal.CSI)
+	if err := term.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := after.String(), "y"+terminal.CSI; got != want {
+		t.Errorf("got %q after Restore, want %q", got, want)
+	}
+}
+
+func TestColorModeNeverKeepsCursorMovement(t *testing.T) {
+	var b bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&b)
+	term.SetColorMode(terminal.ColorNever)
+	term.Print(terminal.FgRed + terminal.SetBright(true) + "x")
+	term.MoveToX(0)
+	if got, want := b.String(), terminal.SetBright(true)+"x"+terminal.MoveToX(0); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestOverrideOutAfterInit(t *testing.T) {
+	var before, after bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&before)
+	term.Print("x")
+	term.OverrideOut(&after)
+	term.SetColorMode(terminal.ColorNever)
+	term.Print(terminal.FgRed + "y")
+	if before.String() != "x" || after.String() != "y" {
+		t.Errorf("got %q and %q, want \"x\" and \"y\"", before.String(), after.String())
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	vars := []string{"NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE"}
+	saved := make(map[string]string)
+	for _, v := range vars {
+		if s, ok := os.LookupEnv(v); ok {
+			saved[v] = s
+		}
+	}
+	defer func() {
+		for _, v := range vars {
+			if s, ok := saved[v]; ok {
+				_ = os.Setenv(v, s)
+			} else {
+				_ = os.Unsetenv(v)
+			}
+		}
+	}()
+	tests := []struct {
+		noColor, cliColor, cliColorForce string
+		want                             terminal.ColorMode
+	}{
+		{"", "", "", terminal.ColorAuto},
+		{"1", "", "", terminal.ColorNever},
+		{"", "0", "", terminal.ColorNever},
+		{"", "1", "", terminal.ColorAuto},
+		{"", "0", "1", terminal.ColorAlways},
+		{"", "", "0", terminal.ColorAuto},
+		{"1", "", "1", terminal.ColorNever},
+	}
+	for _, tt := range tests {
+		for v, s := range map[string]string{"NO_COLOR": tt.noColor, "CLICOLOR": tt.cliColor, "CLICOLOR_FORCE": tt.cliColorForce} {
+			if s == "" {
+				_ = os.Unsetenv(v)
+			} else {
+				_ = os.Setenv(v, s)
+			}
+		}
+		if got := terminal.DetectColorMode(); got != tt.want {
+			t.Errorf("NO_COLOR=%q CLICOLOR=%q CLICOLOR_FORCE=%q: got %v, want %v", tt.noColor, tt.cliColor, tt.cliColorForce, got, tt.want)
+		}
+	}
+}
+
+func TestPalette256RoundTrip(t *testing.T) {
+	for n := 16; n < 256; n++ {
+		if got := terminal.RGBTo256(terminal.RGBFrom256(n)); got != n {
+			t.Errorf("RGBTo256(RGBFrom256(%d)) = %d", n, got)
+		}
+	}
+	if got, want := terminal.Fg256(208), terminal.CSI+"38;5;208m"; got != want {
+		t.Errorf("Fg256(208) = %q, want %q", got, want)
+	}
+	if got := terminal.Bg256(256); got != "" {
+		t.Errorf("Bg256(256) = %q, want empty", got)
+	}
+	if got, want := terminal.ANSI16.Convert(terminal.Fg256(196)), terminal.FgHiRed; got != want {
+		t.Errorf("ANSI16.Convert(Fg256(196)) = %q, want %q", got, want)
+	}
+}
+
+func TestStyleRender(t *testing.T) {
+	tests := []struct {
+		style terminal.Style
+		want  string
+	}{
+		{terminal.Style{}, "x"},
+		{terminal.Style{Fg: terminal.Red}, terminal.CSI + "31mx" + terminal.CSI + "39m"},
+		{terminal.Style{Fg: terminal.RGB(1, 2, 3), Bg: terminal.Indexed(200)}, terminal.CSI + "38;2;1;2;3;48;5;200mx" + terminal.CSI + "39;49m"},
+		{terminal.Style{Bold: true, Dim: true, Underline: terminal.UnderlineCurly}, terminal.CSI + "1;2;4:3mx" + terminal.CSI + "22;24m"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.Render("x"); got != tt.want {
+			t.Errorf("%+v.Render() = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+	from := terminal.Style{Bold: true, Dim: true, Fg: terminal.Red}
+	to := terminal.Style{Bold: true, Fg: terminal.Red, Italic: true}
+	if got, want := from.Transition(to), terminal.CSI+"22;1;3m"; got != want {
+		t.Errorf("Transition() = %q, want %q", got, want)
+	}
+}
+
+func TestStyleUnderlineColor(t *testing.T) {
+	s := terminal.Style{Underline: terminal.UnderlineDouble, UnderlineColor: terminal.Red, Overline: true}
+	if got, want := s.Render("x"), terminal.CSI+"4:2;53;58;5;1mx"+terminal.CSI+"24;55;59m"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got, want := terminal.NoColor.Convert(terminal.UnderlineRGB(1, 2, 3)+terminal.SetItalic(true)), terminal.SetItalic(true); got != want {
+		t.Errorf("NoColor.Convert() = %q, want %q", got, want)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want terminal.Color
+	}{
+		{"#ff8800", terminal.RGB(255, 136, 0)},
+		{"#F80", terminal.RGB(255, 136, 0)},
+		{"rgb(255,136,0)", terminal.RGB(255, 136, 0)},
+		{"rgb(100% 50% 0%)", terminal.RGB(255, 128, 0)},
+		{"rgb(300, -5, 0)", terminal.RGB(255, 0, 0)},
+		{"hsl(32, 100%, 50%)", terminal.RGB(255, 136, 0)},
+		{"hsl(240deg 100% 25%)", terminal.RGB(0, 0, 128)},
+		{"RebeccaPurple", terminal.RGB(0x66, 0x33, 0x99)},
+		{"208", terminal.Indexed(208)},
+		{"default", terminal.DefaultColor},
+	}
+	for _, tt := range tests {
+		got, err := terminal.ParseColor(tt.in)
+		if err != nil {
+			t.Errorf("ParseColor(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		var c terminal.Color
+		if err := c.UnmarshalText([]byte(got.String())); err != nil || c != got {
+			t.Errorf("UnmarshalText(%q) = %v, %v", got.String(), c, err)
+		}
+	}
+	for _, in := range []string{"", "#12", "#ggg", "rgb(1,2)", "hsl(a, 1%, 1%)", "nocolor", "256"} {
+		if _, err := terminal.ParseColor(in); err == nil {
+			t.Errorf("ParseColor(%q) succeeded", in)
+		}
+	}
+	if got, want := terminal.FgRGB(300, -1, 5), terminal.FgRGB(255, 0, 5); got != want {
+		t.Errorf("FgRGB() = %q, want %q", got, want)
+	}
+}
+
+func TestColorSpaces(t *testing.T) {
+	if got := terminal.ContrastRatio(terminal.RGB(0, 0, 0), terminal.RGB(255, 255, 255)); math.Abs(got-21) > 1e-9 {
+		t.Errorf("ContrastRatio(black, white) = %v, want 21", got)
+	}
+	for _, c := range []terminal.Color{terminal.RGB(255, 136, 0), terminal.RGB(18, 52, 86), terminal.Indexed(99)} {
+		if got := terminal.HSL(c.HSL()); got.String() != terminal.RGB(c.RGB()).String() {
+			t.Errorf("HSL round trip of %v = %v", c, got)
+		}
+		if got := terminal.OKLCH(c.OKLCH()); got.String() != terminal.RGB(c.RGB()).String() {
+			t.Errorf("OKLCH round trip of %v = %v", c, got)
+		}
+	}
+	from, to := terminal.RGB(255, 0, 0), terminal.RGB(0, 0, 255)
+	if got := terminal.Blend(from, to, 0); got != from {
+		t.Errorf("Blend(0) = %v, want %v", got, from)
+	}
+	if got := terminal.Blend(from, to, 1); got != to {
+		t.Errorf("Blend(1) = %v, want %v", got, to)
+	}
+	colors := terminal.DistinctColors(6)
+	for i := range colors {
+		for j := i + 1; j < len(colors); j++ {
+			if colors[i] == colors[j] {
+				t.Errorf("DistinctColors(6) has duplicates: %v", colors)
+			}
+		}
+	}
+	if got, want := terminal.Gradient("a b", from, to), from.Fg()+"a "+to.Fg()+"b"+terminal.DefaultFg(); got != want {
+		t.Errorf("Gradient() = %q, want %q", got, want)
+	}
+}
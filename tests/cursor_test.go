@@ -0,0 +1,51 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestCursorPosition(t *testing.T) {
+	tests := []struct {
+		reply string
+		x, y  int
+	}{
+		{"\x1b[5;10R\x1b[?62c", 9, 4},
+		{"\x1b[1;1R\x1b[?62;4c", 0, 0},
+		{"\x1b[A\x1b[12;3R\x1b[?62c", 2, 11},
+	}
+	for _, test := range tests {
+		term, remote := newPtyTerminal(t, map[string]string{"\x1b[6n": test.reply})
+		x, y, err := term.CursorPosition()
+		if err != nil || x != test.x || y != test.y {
+			t.Errorf("reply %q: CursorPosition() = %d, %d, %v, want %d, %d", test.reply, x, y, err, test.x, test.y)
+		}
+		if out := remote.String(); out != "\x1b[6n\x1b[c" {
+			t.Errorf("requested %q, want %q", out, "\x1b[6n\x1b[c")
+		}
+	}
+
+	term, _ := newPtyTerminal(t, map[string]string{"\x1b[6n": "\x1b[?62c"})
+	if _, _, err := term.CursorPosition(); !errors.Is(err, terminal.ErrNoReply) {
+		t.Errorf("CursorPosition() without a reply = %v, want ErrNoReply", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var piped terminal.Terminal
+	piped.SetInput(r)
+	piped.OverrideOut(io.Discard)
+	if _, _, err := piped.CursorPosition(); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("CursorPosition() of a non-terminal = %v, want ErrNotTerminal", err)
+	}
+}
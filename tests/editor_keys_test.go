@@ -0,0 +1,166 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+// readLines types keys into the editor and reads a line for every want.
+func readLines(t *testing.T, e *terminal.LineEditor, fake *fakeTerminal, keys string, want []string) {
+	t.Helper()
+	fake.Type(keys)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, want := range want {
+		line, err := e.ReadLine(ctx)
+		if err != nil || line != want {
+			t.Errorf("ReadLine() = %q, %v, want %q", line, err, want)
+		}
+	}
+}
+
+func TestLineEditorKeys(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	e := terminal.NewLineEditor(term, "> ")
+	tests := []struct {
+		keys string
+		want string
+	}{
+		{"world\x01hello \r", "hello world"},
+		{"ac\x1b[Db\x1b[C\x1b[Cd\r", "abcd"},
+		{"ab\x02\x02x\x06\x05y\r", "xaby"},
+		{"one two\x1b[1;5Dnew \r", "one new two"},
+		{"one two\x01\x1bf!\x1b[1;5C?\r", "one! two?"},
+		{"one two\x1bbx\x1b[H\x1b[Fy\r", "one xtwoy"},
+		{"abc\x7f\x02\x1b[3~\r", "a"},
+		{"one two three\x17\x17\x19\r", "one two three"},
+		{"one two\x1b\x7f\x19\x19\r", "one twotwo"},
+		{"abc def\x01\x0b\x19\x19\r", "abc defabc def"},
+		{"abc def\x01\x1bd\r", " def"},
+		{"abc def\x1b[D\x1b[D\x15\x05\x19\r", "efabc d"},
+	}
+	for _, test := range tests {
+		readLines(t, e, fake, test.keys, []string{test.want})
+	}
+	fake.Type("x\x03\x04")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := e.ReadLine(ctx); err != terminal.ErrInterrupted {
+		t.Errorf("got %v after Ctrl+C, want ErrInterrupted", err)
+	}
+	if _, err := e.ReadLine(ctx); err != io.EOF {
+		t.Errorf("got %v after Ctrl+D, want io.EOF", err)
+	}
+}
+
+func TestLineEditorHistoryKeys(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	e := terminal.NewLineEditor(term, "> ")
+	e.AddHistory("first")
+	e.AddHistory("second")
+	readLines(t, e, fake, "\x1b[A\r"+"\x10\x10\r"+"draft\x1b[A\x1b[A\x0e\x1b[B\r", []string{"second", "first", "draft"})
+	if got, want := e.History(), []string{"first", "second", "first", "draft"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %q, want %q", got, want)
+	}
+	readLines(t, e, fake, "\x12sec\r"+"\x12s\x12\x12x\r"+"ab\x12fir\x07c\r"+"\x12zzz\r", []string{"second", "second", "abc", ""})
+	if out := fake.waitFor("(failed reverse-i-search)`sx': second"); !strings.Contains(out, "(failed reverse-i-search)`sx': second") {
+		t.Errorf("output %q doesn't show the failed search", out)
+	}
+}
+
+func TestLineEditorComplete(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	e := terminal.NewLineEditor(term, "> ")
+	e.Complete = func(line string, pos int) (int, []string) {
+		start := strings.LastIndex(line[:pos], " ") + 1
+		var candidates []string
+		for _, c := range []string{"commit", "status", "stash"} {
+			if strings.HasPrefix(c, line[start:pos]) {
+				candidates = append(candidates, c)
+			}
+		}
+		return start, candidates
+	}
+	readLines(t, e, fake, "git co\t\r"+"git st\t\r"+"co x\x1b[D\x1b[D\t\r", []string{"git commit", "git sta", "commit x"})
+	readLines(t, e, fake, "sta\t\t\r", []string{"sta"})
+	if out := fake.waitFor("\r\nstatus  stash"); !strings.Contains(out, "\r\nstatus  stash") {
+		t.Errorf("output %q doesn't list the candidates", out)
+	}
+}
+
+func TestReadSecretRaw(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	long := strings.Repeat("a", 80)
+	fake.Type("ab\x7fc\rxyz\x15pq\r" + long + "\r\x04")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tests := []struct {
+		opts terminal.SecretOptions
+		want string
+	}{
+		{terminal.SecretOptions{Mask: '*'}, "ac"},
+		{terminal.SecretOptions{}, "pq"},
+		{terminal.SecretOptions{MaxLength: 70}, long[:70]},
+	}
+	for _, test := range tests {
+		test.opts.Context = ctx
+		secret, err := term.ReadSecret("Password: ", test.opts)
+		if err != nil || string(secret) != test.want {
+			t.Errorf("ReadSecret() = %q, %v, want %q", secret, err, test.want)
+		}
+	}
+	if _, err := term.ReadSecret("Password: ", terminal.SecretOptions{Context: ctx}); err != io.EOF {
+		t.Errorf("got %v after Ctrl+D, want io.EOF", err)
+	}
+	out := fake.waitFor("\a\a\a\a\a\a\a\a\a\a")
+	if want := "Password: **\b \b*\r\nPassword: \r\n"; !strings.HasPrefix(out, want) {
+		t.Errorf("output %q, want it to start with %q", out, want)
+	}
+	if got := strings.Count(out, "\a"); got != 10 {
+		t.Errorf("rang the bell %d times beyond MaxLength, want 10", got)
+	}
+}
+
+func TestReadSecretOutputRedirected(t *testing.T) {
+	slave, fake := newPty(t, nil)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	term := terminal.NewTerminal(w)
+	term.SetInput(slave)
+	prompt := make(chan string)
+	go func() {
+		buf := make([]byte, len("Password: "))
+		io.ReadFull(r, buf)
+		fake.Type("hunter2\r") // Once the prompt tells that echo is off
+		prompt <- string(buf)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	secret, err := term.ReadSecret("Password: ", terminal.SecretOptions{Mask: '*', Context: ctx})
+	if err != nil || string(secret) != "hunter2" {
+		t.Errorf("ReadSecret() = %q, %v, want %q", secret, err, "hunter2")
+	}
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if got, want := <-prompt+string(out), "Password: \r\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+	// Echo is back on once the secret is read
+	fake.Type("x")
+	if echo := fake.waitFor("x"); echo != "x" {
+		t.Errorf("terminal echoed %q, want only %q", echo, "x")
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestLineEditorPlain(t *testing.T) {
+	term, w := pipeTerminal(t)
+	e := terminal.NewLineEditor(term, "> ")
+	go func() {
+		w.Write([]byte("first line\r\nsecond\x1b[A\tx\n\r\nlast"))
+		w.Close()
+	}()
+	ctx := context.Background()
+	for _, want := range []string{"first line", "second\x1b[A\tx", "", "last"} {
+		line, err := e.ReadLine(ctx)
+		if err != nil || line != want {
+			t.Errorf("ReadLine() = %q, %v, want %q", line, err, want)
+		}
+	}
+	if _, err := e.ReadLine(ctx); err != io.EOF {
+		t.Errorf("got %v at the end, want io.EOF", err)
+	}
+}
+
+func TestLineEditorHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var term terminal.Terminal
+	e := terminal.NewLineEditor(&term, "> ")
+	e.HistoryLimit = 2
+	if err := e.SetHistoryFile(path); err != nil {
+		t.Fatal(err)
+	}
+	e.AddHistory("four")
+	e.AddHistory("four")
+	e.AddHistory(" ")
+	if got, want := e.History(), []string{"three", "four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %q, want %q", got, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "two\nthree\nfour\n"; got != want {
+		t.Errorf("history file = %q, want %q", got, want)
+	}
+}
+
+func TestReadSecretPlain(t *testing.T) {
+	term, w := pipeTerminal(t)
+	go func() {
+		w.Write([]byte("pässword\r\nsecond"))
+		w.Close()
+	}()
+	opts := terminal.SecretOptions{Mask: '*', MaxLength: 4}
+	for _, want := range []string{"päss", "seco"} {
+		secret, err := term.ReadSecret("Password: ", opts)
+		if err != nil || string(secret) != want {
+			t.Errorf("ReadSecret() = %q, %v, want %q", secret, err, want)
+		}
+	}
+	if _, err := term.ReadSecret("Password: ", opts); err != io.EOF {
+		t.Errorf("got %v at the end, want io.EOF", err)
+	}
+}
+
+func TestReadSecretPlainLong(t *testing.T) {
+	term, w := pipeTerminal(t)
+	long := strings.Repeat("0123456789", 2000) // Longer than the read buffer
+	go func() {
+		w.Write([]byte(long + "\n"))
+		w.Close()
+	}()
+	secret, err := term.ReadSecret("Password: ", terminal.SecretOptions{})
+	if err != nil || string(secret) != long {
+		t.Errorf("ReadSecret() = %d bytes, %v, want %d", len(secret), err, len(long))
+	}
+}
@@ -0,0 +1,57 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestStopInput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var term terminal.Terminal
+	term.OverrideOut(io.Discard)
+	term.SetInput(r)
+	defer term.StopInput() // Before closing the input
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	w.Write([]byte("a"))
+	if k, err := term.ReadKey(ctx); err != nil || k.String() != "a" {
+		t.Fatalf("ReadKey() = %v, %v, want a", k, err)
+	}
+	stopped := make(chan error)
+	go func() {
+		_, err := term.ReadKey(ctx)
+		stopped <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	term.StopInput()
+	if err := <-stopped; !errors.Is(err, terminal.ErrInputStopped) {
+		t.Errorf("ReadKey() waiting during StopInput() = %v, want ErrInputStopped", err)
+	}
+
+	// The input is no longer read in the background
+	w.Write([]byte("b"))
+	buf := make([]byte, 1)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "b" {
+		t.Errorf("Read() after StopInput() = %q, %v, want %q", buf[:n], err, "b")
+	}
+
+	w.Write([]byte("c"))
+	if k, err := term.ReadKey(ctx); err != nil || k.String() != "c" {
+		t.Errorf("ReadKey() after StopInput() = %v, %v, want c", k, err)
+	}
+}
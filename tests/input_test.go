@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+// pipeTerminal returns a terminal reading input from the returned writer.
+func pipeTerminal(t *testing.T) (*terminal.Terminal, *os.File) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	var term terminal.Terminal
+	term.OverrideOut(io.Discard)
+	term.SetInput(r)
+	term.SetEscapeTimeout(20 * time.Millisecond)
+	return &term, w
+}
+
+func TestReadKey(t *testing.T) {
+	term, w := pipeTerminal(t)
+	input := "aЖ\r\x03\x1b[A\x1b[1;5C\x1bOP\x1b[15;2~\x1b[Z\x1bx\x1b\x7f\x1b\x1b[B\x1b[[A\x1b"
+	want := []string{"a", "Ж", "Enter", "Ctrl+C", "Up", "Ctrl+Right", "F1", "Shift+F5", "Shift+Tab", "Alt+x", "Alt+Backspace", "Alt+Down", "F1", "Escape"}
+	go w.Write([]byte(input))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, s := range want {
+		ev, err := term.ReadKey(ctx)
+		if err != nil {
+			t.Fatalf("ReadKey: %v, want %s", err, s)
+		}
+		if got := ev.String(); got != s {
+			t.Errorf("got %s, want %s", got, s)
+		}
+	}
+	w.Close()
+	if _, err := term.ReadKey(ctx); err != io.EOF {
+		t.Errorf("got %v at the end, want io.EOF", err)
+	}
+}
+
+func TestReadKeySplitSequence(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	go func() {
+		w.Write([]byte("\x1b["))
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte("1;3D"))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ev, err := term.ReadKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Key != terminal.KeyLeft || ev.Mod != terminal.ModAlt {
+		t.Errorf("got %s, want Alt+Left", ev)
+	}
+}
+
+func TestReadEventMouseAndFocus(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	go w.Write([]byte("\x1b[<0;10;5M\x1b[<0;10;5m\x1b[<65;1;1M\x1b[<52;300;2M\x1b[M #!\x1b[Ox\x1b[I"))
+	want := []terminal.Event{
+		terminal.MouseEvent{X: 9, Y: 4, Button: terminal.MouseLeft},
+		terminal.MouseEvent{X: 9, Y: 4, Button: terminal.MouseLeft, Action: terminal.MouseRelease},
+		terminal.MouseEvent{Button: terminal.MouseWheelDown},
+		terminal.MouseEvent{X: 299, Y: 1, Button: terminal.MouseLeft, Action: terminal.MouseMotion, Modifiers: terminal.ModCtrl | terminal.ModShift},
+		terminal.MouseEvent{X: 2, Y: 0, Button: terminal.MouseLeft},
+		terminal.FocusEvent{Focused: false},
+		terminal.KeyEvent{Rune: 'x'},
+		terminal.FocusEvent{Focused: true},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, ev := range want {
+		got, err := term.ReadEvent(ctx)
+		if err != nil {
+			t.Fatalf("ReadEvent: %v, want %+v", err, ev)
+		}
+		if got != ev {
+			t.Errorf("got %+v, want %+v", got, ev)
+		}
+	}
+}
+
+func TestReadEventPaste(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	term.SetMaxPasteSize(10)
+	go func() {
+		w.Write([]byte("a\x1b[200~line 1\r\nline 2\x1b[2"))
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("01~b\x1b[200~0123456789\x1b[201~"))
+	}()
+	want := []terminal.Event{
+		terminal.KeyEvent{Rune: 'a'},
+		terminal.PasteEvent{Text: "line 1\nli", Truncated: true},
+		terminal.KeyEvent{Rune: 'b'},
+		terminal.PasteEvent{Text: "0123456789"},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, ev := range want {
+		got, err := term.ReadEvent(ctx)
+		if err != nil {
+			t.Fatalf("ReadEvent: %v, want %+v", err, ev)
+		}
+		if got != ev {
+			t.Errorf("got %+v, want %+v", got, ev)
+		}
+	}
+}
+
+func TestReadKeyKitty(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	go w.Write([]byte("\x1b[105;5u\x1b[9u\x1b[27u\x1b[97;2u\x1b[49:33;2u\x1b[97;1:3u\x1b[1;5:2A\x1b[57441;2u\x1b[57399u"))
+	want := []terminal.KeyEvent{
+		{Rune: 'i', Mod: terminal.ModCtrl},
+		{Key: terminal.KeyTab},
+		{Key: terminal.KeyEscape},
+		{Rune: 'A'},
+		{Rune: '!'},
+		{Rune: 'a', Action: terminal.KeyRelease},
+		{Key: terminal.KeyUp, Mod: terminal.ModCtrl, Action: terminal.KeyRepeat},
+		{Rune: '0'},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, ev := range want {
+		got, err := term.ReadKey(ctx)
+		if err != nil {
+			t.Fatalf("ReadKey: %v, want %+v", err, ev)
+		}
+		if got != ev {
+			t.Errorf("got %+v, want %+v", got, ev)
+		}
+	}
+}
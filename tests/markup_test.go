@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestMarkup(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"[red]x", terminal.FgRed + "x" + terminal.DefaultFg()},
+		{"[red::b]error[-]: ok", terminal.CSI + "1;31merror" + terminal.CSI + "22;39m: ok"},
+		{"[red]a[blue]b[-]c[-]d", terminal.FgRed + "a" + terminal.FgBlue + "b" + terminal.FgRed + "c" + terminal.DefaultFg() + "d"},
+		{"[:#102030]x", terminal.BgRGB(16, 32, 48) + "x" + terminal.DefaultBg()},
+		{"[fg=208::u]x", terminal.CSI + "4;38;5;208mx" + terminal.CSI + "24;39m"},
+		{"[bg=gold]x", terminal.BgRGB(255, 215, 0) + "x" + terminal.DefaultBg()},
+		{"[:fg=#102030]x", terminal.FgRGB(16, 32, 48) + "x" + terminal.DefaultFg()},
+		{"[1] [42] [tan] [gold] [1:2] [rgb(1,2,3)]", "[1] [42] [tan] [gold] [1:2] [rgb(1,2,3)]"},
+		{"[::b]a[::B]b", terminal.SetBright(true) + "a" + terminal.SetBright(false) + "b"},
+		{"[[red] [WARN] [1/3]", "[red] [WARN] [1/3]"},
+		{"[red][-][blue]", ""},
+		{"a[-]b", "ab"},
+		{"[red", "[red"},
+	}
+	for _, tt := range tests {
+		if got := terminal.Markup(tt.in); got != tt.want {
+			t.Errorf("Markup(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMprintf(t *testing.T) {
+	var b bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&b)
+	term.SetColorProfile(terminal.NoColor)
+	term.Mprintf("[red::b]error[-]: file [cyan]%s[-] not found", "[x]")
+	if got, want := b.String(), terminal.SetBright(true)+"error"+terminal.SetBright(false)+": file [x] not found"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
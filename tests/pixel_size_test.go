@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestQueryPixelSize(t *testing.T) {
+	tests := []struct {
+		reply       string
+		wantW       int
+		wantH       int
+		wantNoReply bool
+	}{
+		{"\x1b[4;600;1000t\x1b[6;20;10t\x1b[?62c", 1000, 600, false},
+		{"\x1b[6;20;10t\x1b[?62c", 1000, 600, false},
+		{"\x1b[?62c", 0, 0, true},
+	}
+	for _, test := range tests {
+		term, _ := newPtyTerminal(t, map[string]string{"\x1b[14t": test.reply})
+		width, height, err := term.QueryPixelSize(time.Second)
+		if test.wantNoReply {
+			if !errors.Is(err, terminal.ErrNoReply) {
+				t.Errorf("reply %q: QueryPixelSize() = %d, %d, %v, want ErrNoReply", test.reply, width, height, err)
+			}
+		} else if err != nil || width != test.wantW || height != test.wantH {
+			t.Errorf("reply %q: QueryPixelSize() = %d, %d, %v, want %d, %d", test.reply, width, height, err, test.wantW, test.wantH)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+	"github.com/zzwx/terminal/prompts"
+)
+
+func TestPromptKeys(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	confirms := []struct {
+		keys string
+		def  bool
+		want bool
+	}{
+		{"\r", true, true},
+		{"\r", false, false},
+		{"xy", false, true},
+		{"N", true, false},
+	}
+	for _, test := range confirms {
+		fake.Type(test.keys)
+		if got, err := prompts.Confirm(ctx, term, "Sure?", test.def); err != nil || got != test.want {
+			t.Errorf("Confirm(%q) with default %v = %v, %v, want %v", test.keys, test.def, got, err, test.want)
+		}
+	}
+
+	options := []string{"apple", "banana", "cherry", "blueberry"}
+	selects := []struct {
+		keys string
+		want int
+	}{
+		{"\r", 0},
+		{"\x1b[B\x1b[B\r", 2},
+		{"\x1b[A\r", 3},
+		{"bl\r", 3},
+		{"an\x1b[B\r", 1},        // The only option left stays under the cursor
+		{"xyz\r\x15\x1b[B\r", 1}, // Enter does nothing with no options shown
+	}
+	for _, test := range selects {
+		fake.Type(test.keys)
+		if got, err := prompts.Select(ctx, term, "Pick", options); err != nil || got != test.want {
+			t.Errorf("Select(%q) = %v, %v, want %v", test.keys, got, err, test.want)
+		}
+	}
+
+	fake.Type(" \x1b[B \x1b[B \x1b[B \x1b[A \r")
+	got, err := prompts.MultiSelect(ctx, term, "Pick", options)
+	if want := []int{0, 1, 3}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MultiSelect() = %v, %v, want %v", got, err, want)
+	}
+	fake.Type("ch \x15\r")
+	got, err = prompts.MultiSelect(ctx, term, "Pick", options)
+	if want := []int{2}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MultiSelect() with a filter = %v, %v, want %v", got, err, want)
+	}
+
+	validate := func(s string) error {
+		if s == "" {
+			return errors.New("empty")
+		}
+		return nil
+	}
+	fake.Type("\x15\rx\x1b[D\x7fy\x1b[Fz\r")
+	if got, err := prompts.Input(ctx, term, "Name", "ab", validate); err != nil || got != "yxz" {
+		t.Errorf("Input() = %q, %v, want %q", got, err, "yxz")
+	}
+	fake.Type("\x03")
+	if _, err := prompts.Input(ctx, term, "Name", "", nil); !errors.Is(err, terminal.ErrInterrupted) {
+		t.Errorf("Input() after Ctrl+C = %v, want ErrInterrupted", err)
+	}
+}
+
+func TestSelectNoOptions(t *testing.T) {
+	term, _ := newPtyTerminal(t, nil)
+	ctx := context.Background()
+	if _, err := prompts.Select(ctx, term, "Pick", nil); !errors.Is(err, prompts.ErrNoOptions) {
+		t.Errorf("Select: got %v, want ErrNoOptions", err)
+	}
+	if _, err := prompts.MultiSelect(ctx, term, "Pick", nil); !errors.Is(err, prompts.ErrNoOptions) {
+		t.Errorf("MultiSelect: got %v, want ErrNoOptions", err)
+	}
+}
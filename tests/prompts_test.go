@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zzwx/terminal"
+	"github.com/zzwx/terminal/prompts"
+)
+
+func TestPromptsNotTerminal(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	ctx := context.Background()
+	if _, err := prompts.Confirm(ctx, term, "Sure?", true); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("Confirm: got %v, want ErrNotTerminal", err)
+	}
+	if _, err := prompts.Select(ctx, term, "Pick", []string{"a", "b"}); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("Select: got %v, want ErrNotTerminal", err)
+	}
+	if _, err := prompts.MultiSelect(ctx, term, "Pick", []string{"a", "b"}); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("MultiSelect: got %v, want ErrNotTerminal", err)
+	}
+	if _, err := prompts.Input(ctx, term, "Name", "", nil); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("Input: got %v, want ErrNotTerminal", err)
+	}
+}
@@ -0,0 +1,142 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+	"golang.org/x/sys/unix"
+)
+
+// fakeTerminal is the other end of a pseudo terminal. It records the output and
+// answers queries the way a terminal would, writing replies[request] to the
+// input whenever the output contains request.
+type fakeTerminal struct {
+	master  *os.File
+	mu      sync.Mutex
+	out     bytes.Buffer
+	replies map[string]string
+	sent    map[string]int
+}
+
+// newPtyTerminal returns a terminal attached to a new 100x30 pseudo terminal
+// in raw mode and the fake terminal on the other end.
+func newPtyTerminal(t *testing.T, replies map[string]string) (*terminal.Terminal, *fakeTerminal) {
+	t.Helper()
+	slave, f := newPty(t, replies)
+	term := terminal.NewTerminal(slave)
+	term.SetInput(slave)
+	term.SetRaw(true)
+	return term, f
+}
+
+// newPty opens a new 100x30 pseudo terminal in its default mode, returning its
+// slave side and the fake terminal on the master side.
+func newPty(t *testing.T, replies map[string]string) (*os.File, *fakeTerminal) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("no pseudo terminals:", err)
+	}
+	var n int
+	err = control(master, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		if err := unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: 30, Col: 100}); err != nil {
+			return err
+		}
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		master.Close()
+		t.Fatal(err)
+	}
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		t.Fatal(err)
+	}
+	f := &fakeTerminal{master: master, replies: replies, sent: make(map[string]int)}
+	go f.run()
+	// Closing the master side ends reading from the slave side
+	t.Cleanup(func() { master.Close() })
+	return slave, f
+}
+
+// control calls f with the file descriptor of file without putting it into
+// blocking mode, as file.Fd() does, so that closing it stops reading.
+func control(file *os.File, f func(fd int) error) error {
+	c, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := c.Control(func(fd uintptr) { ferr = f(int(fd)) }); err != nil {
+		return err
+	}
+	return ferr
+}
+
+func (f *fakeTerminal) run() {
+	buf := make([]byte, 1024)
+	for {
+		n, err := f.master.Read(buf)
+		f.mu.Lock()
+		f.out.Write(buf[:n])
+		for request, reply := range f.replies {
+			for c := bytes.Count(f.out.Bytes(), []byte(request)); f.sent[request] < c; f.sent[request]++ {
+				f.master.Write([]byte(reply))
+			}
+		}
+		f.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Type sends keys to the terminal input.
+func (f *fakeTerminal) Type(keys string) {
+	f.master.Write([]byte(keys))
+}
+
+// Resize changes the window size and signals the change with SIGWINCH, which
+// the kernel only sends to the foreground process group of the terminal.
+func (f *fakeTerminal) Resize(width, height int) error {
+	err := control(f.master, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: uint16(height), Col: uint16(width)})
+	})
+	if err != nil {
+		return err
+	}
+	return unix.Kill(unix.Getpid(), unix.SIGWINCH)
+}
+
+// String returns the output so far.
+func (f *fakeTerminal) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.out.String()
+}
+
+// waitFor waits up to a second for the output to contain s and returns it.
+func (f *fakeTerminal) waitFor(s string) string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		out := f.String()
+		if strings.Contains(out, s) || time.Now().After(deadline) {
+			return out
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
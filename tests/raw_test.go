@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestEnterRawNotTerminal(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	for name, enter := range map[string]func() (func() error, error){
+		"EnterRaw":    term.EnterRaw,
+		"EnterCbreak": term.EnterCbreak,
+	} {
+		_, err := enter()
+		var notTerminal *terminal.NotTerminalError
+		if !errors.As(err, &notTerminal) || !errors.Is(err, terminal.ErrNotTerminal) {
+			t.Errorf("%s() = %v, want NotTerminalError", name, err)
+		}
+	}
+	if err := term.ExitRaw(); err != nil {
+		t.Errorf("ExitRaw() = %v, want nil when not raw", err)
+	}
+}
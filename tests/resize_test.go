@@ -0,0 +1,124 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestResizeEvents(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sizes := term.ResizeEvents(ctx)
+	events := term.Events()
+	var mu sync.Mutex
+	var handled [][2]int
+	term.OnResize(func(w, h int) {
+		mu.Lock()
+		handled = append(handled, [2]int{w, h})
+		mu.Unlock()
+	})
+
+	// Resizing with the mouse changes the size many times in a row
+	for width := 101; width <= 120; width++ {
+		if err := fake.Resize(width, 40); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	want := terminal.Size{Width: 120, Height: 40}
+	select {
+	case got := <-sizes:
+		if got != want {
+			t.Errorf("ResizeEvents() received %+v, want %+v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ResizeEvents() received nothing")
+	}
+	select {
+	case ev := <-events:
+		if resize, ok := ev.(terminal.ResizeEvent); !ok || resize.Size != want {
+			t.Errorf("Events() received %#v, want ResizeEvent %+v", ev, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Events() received nothing")
+	}
+
+	// Signalling the same size again is not a change
+	if err := fake.Resize(120, 40); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-sizes:
+		t.Errorf("ResizeEvents() received %+v after no change", got)
+	case ev := <-events:
+		t.Errorf("Events() received %#v after no change", ev)
+	case <-time.After(200 * time.Millisecond):
+	}
+	mu.Lock()
+	if len(handled) != 1 || handled[0] != [2]int{120, 40} {
+		t.Errorf("OnResize handler called with %v, want once with 120, 40", handled)
+	}
+	mu.Unlock()
+
+	cancel()
+	select {
+	case _, ok := <-sizes:
+		if ok {
+			t.Error("ResizeEvents() received a size after ctx is done")
+		}
+	case <-time.After(time.Second):
+		t.Error("ResizeEvents() channel isn't closed when ctx is done")
+	}
+}
+
+func TestOnResizeUnregister(t *testing.T) {
+	term, fake := newPtyTerminal(t, nil)
+	first := make(chan [2]int, 10)
+	unregister := term.OnResize(func(w, h int) { first <- [2]int{w, h} })
+	resize := func(width, height int) {
+		t.Helper()
+		if err := fake.Resize(width, height); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resize(110, 35)
+	select {
+	case got := <-first:
+		if got != [2]int{110, 35} {
+			t.Errorf("OnResize handler called with %v, want 110, 35", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnResize handler not called")
+	}
+
+	unregister()
+	unregister() // Does nothing
+	resize(120, 40)
+	time.Sleep(200 * time.Millisecond)
+
+	// Watching starts again with the next handler
+	second := make(chan [2]int, 10)
+	defer term.OnResize(func(w, h int) { second <- [2]int{w, h} })()
+	resize(90, 20)
+	select {
+	case got := <-second:
+		if got != [2]int{90, 20} {
+			t.Errorf("OnResize handler called with %v, want 90, 20", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnResize handler registered again not called")
+	}
+	select {
+	case got := <-first:
+		t.Errorf("unregistered OnResize handler called with %v", got)
+	default:
+	}
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestSessionRestore(t *testing.T) {
+	var buf bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&buf)
+	term.Session()
+	term.StartAlternativeBuffer().SetCursorVisible(false).SetBracketedPaste(true)
+	term.SetMouseButtons(true).SetBracketedPaste(false).SetCursorVisible(false)
+	buf.Reset()
+	if err := term.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "\x1b[?1000l\x1b[?25h\x1b[?1049l"; got != want {
+		t.Errorf("Restore() printed %q, want %q", got, want)
+	}
+	buf.Reset()
+	term.SetCursorVisible(false)
+	if err := term.Restore(); err != nil || buf.String() != "\x1b[?25l" {
+		t.Errorf("Restore() after the session printed %q, %v", buf.String(), err)
+	}
+}
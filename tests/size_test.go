@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zzwx/terminal"
+)
+
+func TestSizeFromEnvironment(t *testing.T) {
+	term, w := pipeTerminal(t)
+	defer w.Close()
+	vars := []string{"COLUMNS", "LINES"}
+	saved := make(map[string]string)
+	for _, v := range vars {
+		if s, ok := os.LookupEnv(v); ok {
+			saved[v] = s
+		}
+	}
+	defer func() {
+		for _, v := range vars {
+			if s, ok := saved[v]; ok {
+				_ = os.Setenv(v, s)
+			} else {
+				_ = os.Unsetenv(v)
+			}
+		}
+	}()
+	_ = os.Unsetenv("COLUMNS")
+	_ = os.Unsetenv("LINES")
+	if _, err := term.Size(); err == nil {
+		t.Error("Size() of a pipe succeeded without COLUMNS and LINES")
+	}
+	if w, h := term.GetSize(); w != 80 || h != 24 {
+		t.Errorf("GetSize() = %d, %d, want 80, 24", w, h)
+	}
+	_ = os.Setenv("COLUMNS", "132")
+	_ = os.Setenv("LINES", "43")
+	size, err := term.Size()
+	if want := (terminal.Size{Width: 132, Height: 43}); err != nil || size != want {
+		t.Errorf("Size() = %+v, %v, want %+v", size, err, want)
+	}
+	if w, h := term.GetSize(); w != 132 || h != 43 {
+		t.Errorf("GetSize() = %d, %d, want 132, 43", w, h)
+	}
+
+	remote := terminal.NewTerminalFromStreams(strings.NewReader(""), io.Discard, terminal.StreamOptions{IsTerminal: true})
+	if size, err := remote.Size(); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("Size() of streams without a size = %+v, %v, want NotTerminalError", size, err)
+	}
+	if w, h := remote.GetSize(); w != 80 || h != 24 {
+		t.Errorf("GetSize() of streams without a size = %d, %d, want 80, 24", w, h)
+	}
+}
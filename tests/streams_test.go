@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zzwx/terminal"
+)
+
+// remoteTerminal records the output and answers queries the way a terminal on
+// the other end of a connection would, writing replies[request] to the input
+// when the output contains request.
+type remoteTerminal struct {
+	mu      sync.Mutex
+	out     bytes.Buffer
+	input   *io.PipeWriter
+	replies map[string]string
+}
+
+// newRemoteTerminal returns a terminal connected to a remoteTerminal with a
+// 100x30 window, and the pipe to type into it.
+func newRemoteTerminal(replies map[string]string) (*terminal.Terminal, *io.PipeWriter, *remoteTerminal) {
+	in, w := io.Pipe()
+	remote := &remoteTerminal{input: w, replies: replies}
+	term := terminal.NewTerminalFromStreams(in, remote, terminal.StreamOptions{
+		IsTerminal: true,
+		Size:       terminal.Size{Width: 100, Height: 30},
+		Profile:    terminal.ANSI256,
+	})
+	return term, w, remote
+}
+
+func (r *remoteTerminal) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for request, reply := range r.replies {
+		if bytes.Contains(p, []byte(request)) {
+			go r.input.Write([]byte(reply))
+		}
+	}
+	return r.out.Write(p)
+}
+
+func (r *remoteTerminal) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.out.String()
+}
+
+func TestNewTerminalFromStreams(t *testing.T) {
+	term, w, remote := newRemoteTerminal(map[string]string{"\x1b[?u": "\x1b[?5u\x1b[?62c"})
+	defer w.Close()
+	if !term.IsTerminal() || term.ColorProfile() != terminal.ANSI256 {
+		t.Errorf("IsTerminal() = %v, ColorProfile() = %v", term.IsTerminal(), term.ColorProfile())
+	}
+	term.MoveToX(2).Print(terminal.FgRGB(255, 0, 0) + "red")
+	if got, want := remote.String(), "\x1b[3G\x1b[38;5;196mred"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+	restore, err := term.EnterRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+	flags, err := term.QueryKeyboardFlags(time.Second)
+	if err != nil || flags != 5 {
+		t.Errorf("QueryKeyboardFlags() = %v, %v, want 5", flags, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go w.Write([]byte("x\x1b[A"))
+	for _, want := range []string{"x", "Up"} {
+		k, err := term.ReadKey(ctx)
+		if err != nil || k.String() != want {
+			t.Errorf("ReadKey() = %v, %v, want %s", k, err, want)
+		}
+	}
+	sizes := term.ResizeEvents(ctx)
+	for width := 101; width <= 120; width++ { // Delivered once settled
+		term.SetSize(terminal.Size{Width: width, Height: 40})
+	}
+	if got := <-sizes; got.Width != 120 || got.Height != 40 {
+		t.Errorf("ResizeEvents() received %+v", got)
+	}
+	ev, err := term.ReadEvent(ctx)
+	if resize, ok := ev.(terminal.ResizeEvent); err != nil || !ok || resize.Width != 120 {
+		t.Errorf("ReadEvent() = %#v, %v, want ResizeEvent", ev, err)
+	}
+	if w, h := term.GetSize(); w != 120 || h != 40 {
+		t.Errorf("GetSize() = %d, %d, want 120, 40", w, h)
+	}
+}
+
+func TestNewTerminalFromStreamsProfile(t *testing.T) {
+	tests := []struct {
+		opts terminal.StreamOptions
+		want terminal.ColorProfile
+	}{
+		{terminal.StreamOptions{IsTerminal: true}, terminal.ANSI16},
+		{terminal.StreamOptions{IsTerminal: true, Term: "xterm-256color"}, terminal.ANSI256},
+		{terminal.StreamOptions{IsTerminal: true, Term: "xterm-direct"}, terminal.TrueColor},
+		{terminal.StreamOptions{IsTerminal: true, Term: "xterm-256color", Profile: terminal.TrueColor}, terminal.TrueColor},
+	}
+	for _, test := range tests {
+		var out strings.Builder
+		term := terminal.NewTerminalFromStreams(strings.NewReader(""), &out, test.opts)
+		if got := term.ColorProfile(); got != test.want {
+			t.Errorf("%+v: ColorProfile() = %v, want %v", test.opts, got, test.want)
+		}
+		term.Print(terminal.FgRGB(255, 0, 0) + "red")
+		if !strings.Contains(out.String(), "\x1b[") {
+			t.Errorf("%+v: output %q has no colors", test.opts, out.String())
+		}
+	}
+}
+
+func TestNewTerminalFromStreamsNotTerminal(t *testing.T) {
+	var out strings.Builder
+	term := terminal.NewTerminalFromStreams(strings.NewReader(""), &out, terminal.StreamOptions{Profile: terminal.TrueColor})
+	term.MoveToX(2).Print(terminal.FgRGB(255, 0, 0) + "plain")
+	if got := out.String(); got != "plain" {
+		t.Errorf("output %q, want %q", got, "plain")
+	}
+	if _, err := term.EnterRaw(); !errors.Is(err, terminal.ErrNotTerminal) {
+		t.Errorf("EnterRaw() = %v, want NotTerminalError", err)
+	}
+}
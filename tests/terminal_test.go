@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"github.com/zzwx/terminal"
 	"io"
@@ -9,8 +10,6 @@ import (
 	"strconv"
 	"testing"
 	"time"
-
-	sshT "golang.org/x/crypto/ssh/terminal"
 )
 
 func mainChat() {
@@ -20,25 +19,15 @@ func mainChat() {
 }
 
 func chat() error {
-	f := os.Stdout
-	if !sshT.IsTerminal(int(f.Fd())) {
+	t := terminal.NewTerminal(os.Stdout)
+	if !t.IsTerminal() {
 		return fmt.Errorf("stdin/stdout should be terminal")
 	}
-	oldState, err := sshT.MakeRaw(int(f.Fd()))
-	if err != nil {
-		return err
-	}
-	defer sshT.Restore(int(f.Fd()), oldState)
-	screen := struct {
-		io.Reader
-		io.Writer
-	}{os.Stdin, os.Stdout}
-	term := sshT.NewTerminal(screen, "> ")
-	//term.SetPrompt(string(term.Escape.Red) + "> " + string(term.Escape.Reset))
-	rePrefix := string(term.Escape.Cyan) + "Human says:" + string(term.Escape.Reset)
+	e := terminal.NewLineEditor(t, terminal.FgRed+"> "+terminal.Reset)
+	rePrefix := terminal.FgCyan + "Human says:" + terminal.Reset
 	for {
-		line, err := term.ReadLine()
-		if err == io.EOF {
+		line, err := e.ReadLine(context.Background())
+		if err == io.EOF || err == terminal.ErrInterrupted {
 			return nil
 		}
 		if err != nil {
@@ -47,7 +36,7 @@ func chat() error {
 		if line == "" {
 			continue
 		}
-		fmt.Fprintln(term, rePrefix, line)
+		t.Println(rePrefix, line)
 	}
 }
 
@@ -102,8 +91,7 @@ func TestTerminal2(t_ *testing.T) {
 
 	t.SetRaw(true)
 
-	var buffer [1]byte
-	_, _ = os.Stdin.Read(buffer[:])
+	_, _ = t.ReadKey(context.Background())
 
 	t.SetRaw(false)
 
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/zzwx/terminal"
+	"github.com/zzwx/terminal/terminfo"
+)
+
+func TestTerminfoExpand(t *testing.T) {
+	tests := []struct {
+		s      string
+		params []int
+		want   string
+	}{
+		{"\x1b[%i%p1%d;%p2%dH", []int{4, 9}, "\x1b[5;10H"},
+		{"\x1b[%p1%dX$<5>", []int{3}, "\x1b[3X"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []int{2}, "\x1b[32m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []int{10}, "\x1b[92m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []int{200}, "\x1b[38;5;200m"},
+		{"%p1%c%p2%'A'%+%c", []int{'x', 1}, "xB"},
+		{"%p1%Pa%ga%ga%*%03d|%p2%:-4d|%p1%x", []int{11, 7}, "121|7   |b"},
+		{"100%%", nil, "100%"},
+	}
+	for _, tt := range tests {
+		if got := terminfo.Expand(tt.s, tt.params...); got != tt.want {
+			t.Errorf("Expand(%q, %v) = %q, want %q", tt.s, tt.params, got, tt.want)
+		}
+	}
+}
+
+// compileEntry builds a legacy format entry having strings at the given
+// standard indexes and a single extended string capability.
+func compileEntry(names string, strs map[int]string, extName, extValue string) []byte {
+	le := func(b *bytes.Buffer, v ...int) {
+		for _, n := range v {
+			binary.Write(b, binary.LittleEndian, int16(n))
+		}
+	}
+	count := 0
+	for i := range strs {
+		if i+1 > count {
+			count = i + 1
+		}
+	}
+	var table bytes.Buffer
+	offsets := make([]int, count)
+	for i := range offsets {
+		offsets[i] = -1
+		if s, ok := strs[i]; ok {
+			offsets[i] = table.Len()
+			table.WriteString(s + "\x00")
+		}
+	}
+	var b bytes.Buffer
+	le(&b, 0432, len(names)+1, 0, 0, count, table.Len())
+	b.WriteString(names + "\x00")
+	if b.Len()%2 == 1 {
+		b.WriteByte(0)
+	}
+	le(&b, offsets...)
+	b.Write(table.Bytes())
+	if b.Len()%2 == 1 {
+		b.WriteByte(0)
+	}
+	extTable := extValue + "\x00" + extName + "\x00"
+	le(&b, 0, 0, 1, 2, len(extTable))
+	le(&b, 0, 0)
+	b.WriteString(extTable)
+	return b.Bytes()
+}
+
+func TestTerminfoTerminal(t *testing.T) {
+	ti, err := terminfo.Parse(compileEntry("test|Test terminal", map[int]string{
+		6:  "\x1b[K$<3>",          // el
+		10: "\x1b[%i%p1%d;%p2%df", // cup
+		13: "\x1b[?1c",            // civis
+	}, "Ss", "\x1b[%p1%d q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ti.Names; len(got) != 2 || got[0] != "test" {
+		t.Errorf("Names = %q", got)
+	}
+	if got, _ := ti.Parm("Ss", 2); got != "\x1b[2 q" {
+		t.Errorf("Ss = %q", got)
+	}
+
+	var b bytes.Buffer
+	var term terminal.Terminal
+	term.OverrideOut(&b)
+	term.SetTerminfo(ti)
+	term.MoveToXY(9, 4).EraseRestOfLine().SetCursorVisible(false).SetCursorVisible(true)
+	want := "\x1b[5;10f" + "\x1b[K" + "\x1b[?1c" + terminal.SetCursorVisible(true)
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTerminfoLoad(t *testing.T) {
+	ti, err := terminfo.Load("xterm")
+	if errors.Is(err, terminfo.ErrNotFound) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ti.Parm("cup", 4, 9); got != terminal.MoveToXY(9, 4) {
+		t.Errorf("cup = %q, want %q", got, terminal.MoveToXY(9, 4))
+	}
+}